@@ -1,45 +1,66 @@
 package main
 
 import (
+	"bufio"
 	"container/heap"
+	"flag"
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/jambii1/task-2-2/internal/input"
 	"github.com/jambii1/task-2-2/internal/maxheap"
 )
 
 func main() {
-	var (
-		dishesAmount uint
-		dishPriority int
-		preference   uint
-	)
+	errorsFormat := flag.String("errors", input.FormatText, "error output format: text or json")
+	flag.Parse()
+
+	err := input.ValidateFormat(*errorsFormat)
+	if err == nil {
+		reader := bufio.NewReader(os.Stdin)
+
+		if flag.NArg() > 0 {
+			err = runSubcommand(reader, flag.Arg(0), flag.Args()[1:])
+		} else {
+			err = run(reader)
+		}
+	}
 
-	_, err := fmt.Scan(&dishesAmount)
 	if err != nil {
-		fmt.Println("invalid number of dishes")
+		reportErr := input.Report(os.Stderr, *errorsFormat, err)
+		if reportErr != nil {
+			fmt.Fprintln(os.Stderr, reportErr)
+		}
+
+		os.Exit(input.ExitCode(err))
+	}
+}
 
-		return
+func run(reader io.Reader) error {
+	dishesAmount, err := input.ReadCount(reader)
+	if err != nil {
+		return err
 	}
 
 	preferences := &maxheap.MaxHeap{}
 	heap.Init(preferences)
 
-	for range dishesAmount {
-		_, err = fmt.Scan(&dishPriority)
-		if err != nil {
-			fmt.Println("invalid dish priority")
-
-			return
-		}
-
-		heap.Push(preferences, dishPriority)
+	err = input.ReadPriorities(reader, dishesAmount, func(priority int) {
+		heap.Push(preferences, priority)
+	})
+	if err != nil {
+		return err
 	}
 
-	_, err = fmt.Scan(&preference)
+	preference, err := input.ReadK(reader)
 	if err != nil {
-		fmt.Println("invalid dish preference")
+		return err
+	}
 
-		return
+	err = input.ValidateK(preference, preferences.Len())
+	if err != nil {
+		return err
 	}
 
 	for range preference - 1 {
@@ -50,4 +71,6 @@ func main() {
 	if ok {
 		fmt.Println(result)
 	}
+
+	return nil
 }
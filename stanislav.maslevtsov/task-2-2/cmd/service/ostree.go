@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/jambii1/task-2-2/internal/input"
+	"github.com/jambii1/task-2-2/internal/ostree"
+)
+
+// removedFlag collects the priorities given with every -remove, the dishes taken
+// off the menu before the query runs.
+func removedFlag(flagSet *flag.FlagSet) *[]int {
+	var removed []int
+
+	flagSet.Func("remove", "priority of a dish to delete before the query, repeatable", func(value string) error {
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse priority: %w", err)
+		}
+
+		removed = append(removed, priority)
+
+		return nil
+	})
+
+	return &removed
+}
+
+func readTree(reader io.Reader, removed []int) (*ostree.Tree, error) {
+	tree := ostree.New()
+
+	err := readPriorities(reader, tree.Insert)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, priority := range removed {
+		if !tree.Delete(priority) {
+			return nil, fmt.Errorf("%w: no dish has priority %d to remove", input.ErrUsage, priority)
+		}
+	}
+
+	return tree, nil
+}
+
+func runSelect(reader io.Reader, args []string) error {
+	flagSet := flag.NewFlagSet("select", flag.ContinueOnError)
+	kth := flagSet.Int("k", 1, "rank of the priority to report, largest first")
+	removed := removedFlag(flagSet)
+
+	err := parseFlags(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	tree, err := readTree(reader, *removed)
+	if err != nil {
+		return err
+	}
+
+	err = input.ValidateK(*kth, tree.Len())
+	if err != nil {
+		return err
+	}
+
+	result, err := tree.Select(tree.Len() - *kth + 1)
+	if err != nil {
+		return fmt.Errorf("failed to select priority: %w", err)
+	}
+
+	fmt.Println(result)
+
+	return nil
+}
+
+func runRank(reader io.Reader, args []string) error {
+	flagSet := flag.NewFlagSet("rank", flag.ContinueOnError)
+	priority := flagSet.Int("p", 0, "priority to rank, largest first")
+	removed := removedFlag(flagSet)
+
+	err := parseFlags(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	tree, err := readTree(reader, *removed)
+	if err != nil {
+		return err
+	}
+
+	// Equal priorities share the rank after every greater one.
+	greater := 0
+	if *priority < math.MaxInt {
+		greater = tree.Len() - tree.Rank(*priority+1)
+	}
+
+	fmt.Println(greater + 1)
+
+	return nil
+}
+
+func runRange(reader io.Reader, args []string) error {
+	flagSet := flag.NewFlagSet("range", flag.ContinueOnError)
+	low := flagSet.Int("lo", math.MinInt, "lower priority bound, inclusive")
+	high := flagSet.Int("hi", math.MaxInt, "upper priority bound, inclusive")
+	removed := removedFlag(flagSet)
+
+	err := parseFlags(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	tree, err := readTree(reader, *removed)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(tree.CountRange(*low, *high))
+
+	return nil
+}
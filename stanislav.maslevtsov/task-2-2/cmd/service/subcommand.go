@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-2-2/internal/input"
+)
+
+func runSubcommand(reader io.Reader, name string, args []string) error {
+	switch name {
+	case "window":
+		return runWindow(reader, args)
+	case "select":
+		return runSelect(reader, args)
+	case "rank":
+		return runRank(reader, args)
+	case "range":
+		return runRange(reader, args)
+	default:
+		return fmt.Errorf("%w: unknown subcommand %q", input.ErrUsage, name)
+	}
+}
+
+func parseFlags(flagSet *flag.FlagSet, args []string) error {
+	flagSet.SetOutput(io.Discard)
+
+	err := flagSet.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: failed to parse %s flags: %w", input.ErrUsage, flagSet.Name(), err)
+	}
+
+	return nil
+}
+
+func readPriorities(reader io.Reader, handle func(priority int)) error {
+	dishesAmount, err := input.ReadCount(reader)
+	if err != nil {
+		return err
+	}
+
+	return input.ReadPriorities(reader, dishesAmount, handle)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-2-2/internal/input"
+	"github.com/jambii1/task-2-2/internal/slidingwindow"
+)
+
+func runWindow(reader io.Reader, args []string) error {
+	flagSet := flag.NewFlagSet("window", flag.ContinueOnError)
+	kth := flagSet.Int("k", 1, "rank of the rating to report")
+	windowSize := flagSet.Int("w", 1, "number of latest orders in the window")
+
+	err := parseFlags(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	err = input.ValidateK(*kth, *windowSize)
+	if err != nil {
+		return err
+	}
+
+	kthLargest, err := slidingwindow.NewKthLargest(*kth, *windowSize)
+	if err != nil {
+		return fmt.Errorf("failed to create window: %w", err)
+	}
+
+	return readPriorities(reader, func(priority int) {
+		result, ok := kthLargest.Push(priority)
+		if ok {
+			fmt.Println(result)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrInvalidCount    = errors.New("invalid number of dishes")
+	ErrInvalidPriority = errors.New("invalid dish priority")
+	ErrInvalidK        = errors.New("invalid dish preference")
+	ErrKOutOfRange     = errors.New("dish preference is out of range")
+	ErrUsage           = errors.New("invalid usage")
+)
+
+func ReadCount(reader io.Reader) (uint, error) {
+	var count uint
+
+	_, err := fmt.Fscan(reader, &count)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidCount, err)
+	}
+
+	return count, nil
+}
+
+func ReadPriorities(reader io.Reader, count uint, handle func(priority int)) error {
+	var priority int
+
+	for index := range count {
+		_, err := fmt.Fscan(reader, &priority)
+		if err != nil {
+			return fmt.Errorf("%w at position %d: %w", ErrInvalidPriority, index+1, err)
+		}
+
+		handle(priority)
+	}
+
+	return nil
+}
+
+func ReadK(reader io.Reader) (int, error) {
+	var kth int
+
+	_, err := fmt.Fscan(reader, &kth)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidK, err)
+	}
+
+	return kth, nil
+}
+
+func ValidateK(kth, count int) error {
+	if kth < 1 || kth > count {
+		return fmt.Errorf("%w: %d is not within [1, %d]", ErrKOutOfRange, kth, count)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Exit codes reported by the task-2-2 service:
+//
+//	0 - success
+//	1 - unexpected failure
+//	2 - invalid command line usage
+//	3 - invalid number of dishes
+//	4 - invalid dish priority
+//	5 - invalid dish preference
+//	6 - dish preference is out of range
+const (
+	ExitOK = iota
+	ExitFailure
+	ExitUsage
+	ExitInvalidCount
+	ExitInvalidPriority
+	ExitInvalidK
+	ExitKOutOfRange
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var ErrUnknownFormat = errors.New("unknown errors format")
+
+type errorRecord struct {
+	Error    string `json:"error"`
+	Kind     string `json:"kind"`
+	ExitCode int    `json:"exit_code"`
+}
+
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	case errors.Is(err, ErrInvalidCount):
+		return ExitInvalidCount
+	case errors.Is(err, ErrInvalidPriority):
+		return ExitInvalidPriority
+	case errors.Is(err, ErrInvalidK):
+		return ExitInvalidK
+	case errors.Is(err, ErrKOutOfRange):
+		return ExitKOutOfRange
+	default:
+		return ExitFailure
+	}
+}
+
+func kind(err error) string {
+	switch ExitCode(err) {
+	case ExitUsage:
+		return "usage"
+	case ExitInvalidCount:
+		return "invalid_count"
+	case ExitInvalidPriority:
+		return "invalid_priority"
+	case ExitInvalidK:
+		return "invalid_k"
+	case ExitKOutOfRange:
+		return "k_out_of_range"
+	default:
+		return "failure"
+	}
+}
+
+func ValidateFormat(format string) error {
+	if format != FormatText && format != FormatJSON {
+		return fmt.Errorf("%w: %w %q", ErrUsage, ErrUnknownFormat, format)
+	}
+
+	return nil
+}
+
+func Report(writer io.Writer, format string, err error) error {
+	if format != FormatJSON {
+		_, err = fmt.Fprintln(writer, err)
+		if err != nil {
+			return fmt.Errorf("failed to write error: %w", err)
+		}
+
+		return nil
+	}
+
+	record := errorRecord{
+		Error:    err.Error(),
+		Kind:     kind(err),
+		ExitCode: ExitCode(err),
+	}
+
+	err = json.NewEncoder(writer).Encode(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package ostree
+
+import (
+	"errors"
+	"math/rand/v2"
+)
+
+var ErrOutOfRange = errors.New("position is out of range")
+
+type node struct {
+	value    int
+	priority uint32
+	size     int
+	left     *node
+	right    *node
+}
+
+// Tree is a treap where every node also stores the size of its subtree,
+// which lets rank and select queries run in expected O(log n).
+type Tree struct {
+	root *node
+}
+
+func New() *Tree {
+	return &Tree{root: nil}
+}
+
+func (tree *Tree) Len() int {
+	return size(tree.root)
+}
+
+func (tree *Tree) Insert(value int) {
+	left, right := split(tree.root, value, false)
+	newNode := &node{
+		value:    value,
+		priority: rand.Uint32(),
+		size:     1,
+		left:     nil,
+		right:    nil,
+	}
+	tree.root = merge(merge(left, newNode), right)
+}
+
+func (tree *Tree) Delete(value int) bool {
+	left, rest := split(tree.root, value, false)
+	middle, right := split(rest, value, true)
+
+	deleted := middle != nil
+	if deleted {
+		middle = merge(middle.left, middle.right)
+	}
+
+	tree.root = merge(merge(left, middle), right)
+
+	return deleted
+}
+
+// Select returns the value at the 1-based position in ascending order.
+func (tree *Tree) Select(position int) (int, error) {
+	if position < 1 || position > tree.Len() {
+		return 0, ErrOutOfRange
+	}
+
+	current := tree.root
+
+	for {
+		leftSize := size(current.left)
+
+		switch {
+		case position <= leftSize:
+			current = current.left
+		case position == leftSize+1:
+			return current.value, nil
+		default:
+			position -= leftSize + 1
+			current = current.right
+		}
+	}
+}
+
+// Rank returns the number of stored values strictly less than value.
+func (tree *Tree) Rank(value int) int {
+	return countBelow(tree.root, value, false)
+}
+
+// CountRange returns the number of stored values within [low, high].
+func (tree *Tree) CountRange(low, high int) int {
+	if low > high {
+		return 0
+	}
+
+	return countBelow(tree.root, high, true) - countBelow(tree.root, low, false)
+}
+
+func size(current *node) int {
+	if current == nil {
+		return 0
+	}
+
+	return current.size
+}
+
+func (current *node) update() {
+	current.size = size(current.left) + size(current.right) + 1
+}
+
+func split(current *node, value int, inclusive bool) (*node, *node) {
+	if current == nil {
+		return nil, nil
+	}
+
+	if current.value < value || (inclusive && current.value == value) {
+		left, right := split(current.right, value, inclusive)
+		current.right = left
+		current.update()
+
+		return current, right
+	}
+
+	left, right := split(current.left, value, inclusive)
+	current.left = right
+	current.update()
+
+	return left, current
+}
+
+func merge(left, right *node) *node {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case left.priority > right.priority:
+		left.right = merge(left.right, right)
+		left.update()
+
+		return left
+	default:
+		right.left = merge(left, right.left)
+		right.update()
+
+		return right
+	}
+}
+
+func countBelow(current *node, value int, inclusive bool) int {
+	count := 0
+
+	for current != nil {
+		if current.value < value || (inclusive && current.value == value) {
+			count += size(current.left) + 1
+			current = current.right
+		} else {
+			current = current.left
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,142 @@
+package ostree_test
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/jambii1/task-2-2/internal/ostree"
+)
+
+// oracle answers the tree queries on a sorted slice.
+type oracle []int
+
+func (sorted oracle) rank(value int) int {
+	return sort.SearchInts(sorted, value)
+}
+
+func (sorted oracle) countRange(low, high int) int {
+	count := 0
+
+	for _, value := range sorted {
+		if low <= value && value <= high {
+			count++
+		}
+	}
+
+	return count
+}
+
+func build(values []int) (*ostree.Tree, oracle) {
+	tree := ostree.New()
+	for _, value := range values {
+		tree.Insert(value)
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	return tree, sorted
+}
+
+func checkQueries(t *testing.T, name string, tree *ostree.Tree, sorted oracle) {
+	t.Helper()
+
+	if tree.Len() != len(sorted) {
+		t.Fatalf("%s: got length %d, want %d", name, tree.Len(), len(sorted))
+	}
+
+	for position := 0; position <= len(sorted)+1; position++ {
+		got, err := tree.Select(position)
+
+		if position < 1 || position > len(sorted) {
+			if !errors.Is(err, ostree.ErrOutOfRange) {
+				t.Errorf("%s: select %d: got error %v, want %v", name, position, err, ostree.ErrOutOfRange)
+			}
+
+			continue
+		}
+
+		if err != nil || got != sorted[position-1] {
+			t.Errorf("%s: select %d: got %d, %v, want %d", name, position, got, err, sorted[position-1])
+		}
+	}
+
+	if len(sorted) == 0 {
+		return
+	}
+
+	low, high := sorted[0]-2, sorted[len(sorted)-1]+2
+
+	for value := low; value <= high; value++ {
+		got := tree.Rank(value)
+		if got != sorted.rank(value) {
+			t.Errorf("%s: rank %d: got %d, want %d", name, value, got, sorted.rank(value))
+		}
+	}
+
+	for from := low; from <= high; from++ {
+		for to := low; to <= high; to++ {
+			got := tree.CountRange(from, to)
+			if got != sorted.countRange(from, to) {
+				t.Errorf("%s: count range [%d, %d]: got %d, want %d", name, from, to, got, sorted.countRange(from, to))
+			}
+		}
+	}
+}
+
+func TestQueries(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+	}{
+		{name: "empty", values: nil},
+		{name: "single", values: []int{5}},
+		{name: "ascending", values: []int{1, 2, 3, 4, 5, 6, 7, 8}},
+		{name: "descending", values: []int{8, 7, 6, 5, 4, 3, 2, 1}},
+		{name: "duplicates", values: []int{4, 2, 4, 4, 9, 2, 0, 9, 4}},
+		{name: "all equal", values: []int{3, 3, 3, 3, 3}},
+		{name: "negative", values: []int{-5, 10, -5, 0, 7, -1}},
+	}
+
+	for _, test := range tests {
+		tree, sorted := build(test.values)
+		checkQueries(t, test.name, tree, sorted)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []int
+		deleted []int
+		missing []int
+	}{
+		{name: "one of duplicates", values: []int{4, 2, 4, 4, 9}, deleted: []int{4}, missing: []int{5}},
+		{name: "every duplicate", values: []int{4, 2, 4, 4, 9}, deleted: []int{4, 4, 4}, missing: []int{4}},
+		{name: "down to empty", values: []int{1, 2}, deleted: []int{2, 1}, missing: []int{1}},
+		{name: "from empty", values: nil, deleted: nil, missing: []int{0}},
+	}
+
+	for _, test := range tests {
+		tree, sorted := build(test.values)
+
+		for _, value := range test.deleted {
+			if !tree.Delete(value) {
+				t.Fatalf("%s: delete %d: value not found", test.name, value)
+			}
+
+			index := sorted.rank(value)
+			sorted = slices.Delete(sorted, index, index+1)
+		}
+
+		for _, value := range test.missing {
+			if tree.Delete(value) {
+				t.Errorf("%s: delete %d: deleted a missing value", test.name, value)
+			}
+		}
+
+		checkQueries(t, test.name, tree, sorted)
+	}
+}
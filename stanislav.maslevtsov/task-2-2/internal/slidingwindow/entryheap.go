@@ -0,0 +1,58 @@
+package slidingwindow
+
+type entry struct {
+	value int
+	seq   uint64
+}
+
+func (left entry) greater(right entry) bool {
+	if left.value != right.value {
+		return left.value > right.value
+	}
+
+	return left.seq > right.seq
+}
+
+type entryHeap struct {
+	entries []entry
+	isMax   bool
+}
+
+func (heap *entryHeap) Len() int {
+	return len(heap.entries)
+}
+
+func (heap *entryHeap) Less(left, right int) bool {
+	if heap.isMax {
+		return heap.entries[left].greater(heap.entries[right])
+	}
+
+	return heap.entries[right].greater(heap.entries[left])
+}
+
+func (heap *entryHeap) Swap(left, right int) {
+	heap.entries[left], heap.entries[right] = heap.entries[right], heap.entries[left]
+}
+
+func (heap *entryHeap) Push(value any) {
+	assertedValue, ok := value.(entry)
+	if ok {
+		heap.entries = append(heap.entries, assertedValue)
+	}
+}
+
+func (heap *entryHeap) Pop() any {
+	heapLen := len(heap.entries)
+	if heapLen == 0 {
+		return nil
+	}
+
+	lastValue := heap.entries[heapLen-1]
+	heap.entries = heap.entries[0 : heapLen-1]
+
+	return lastValue
+}
+
+func (heap *entryHeap) top() entry {
+	return heap.entries[0]
+}
@@ -0,0 +1,7 @@
+package slidingwindow
+
+// HeapLens reports how many entries each heap holds, deleted ones included,
+// and how many of them are live, so tests can watch compaction.
+func (kthLargest *KthLargest) HeapLens() (top, rest, liveTop, liveRest int) {
+	return kthLargest.top.Len(), kthLargest.rest.Len(), kthLargest.topSize, kthLargest.restSize
+}
@@ -0,0 +1,175 @@
+package slidingwindow
+
+import (
+	"container/heap"
+	"errors"
+)
+
+var (
+	ErrInvalidKth    = errors.New("kth must be positive")
+	ErrInvalidWindow = errors.New("window size must be not less than kth")
+)
+
+type side uint8
+
+const (
+	sideTop side = iota
+	sideRest
+)
+
+// KthLargest keeps the k largest values of the window in a min-heap and the
+// others in a max-heap. Values leaving the window are deleted lazily: they are
+// only marked and get dropped once they surface at the top of their heap, or
+// when a heap holds more deleted entries than live ones and is compacted.
+type KthLargest struct {
+	kth        int
+	windowSize int
+
+	top      *entryHeap
+	rest     *entryHeap
+	topSize  int
+	restSize int
+
+	window  []entry
+	nextSeq uint64
+	sides   map[uint64]side
+	deleted map[uint64]struct{}
+}
+
+func NewKthLargest(kth, windowSize int) (*KthLargest, error) {
+	if kth <= 0 {
+		return nil, ErrInvalidKth
+	}
+
+	if windowSize < kth {
+		return nil, ErrInvalidWindow
+	}
+
+	return &KthLargest{
+		kth:        kth,
+		windowSize: windowSize,
+		top:        &entryHeap{isMax: false},
+		rest:       &entryHeap{isMax: true},
+		window:     make([]entry, 0, windowSize),
+		sides:      make(map[uint64]side),
+		deleted:    make(map[uint64]struct{}),
+	}, nil
+}
+
+func (kthLargest *KthLargest) Push(value int) (int, bool) {
+	if len(kthLargest.window) == kthLargest.windowSize {
+		kthLargest.remove(kthLargest.window[0])
+		kthLargest.window = kthLargest.window[1:]
+	}
+
+	newEntry := entry{value: value, seq: kthLargest.nextSeq}
+	kthLargest.nextSeq++
+	kthLargest.window = append(kthLargest.window, newEntry)
+
+	kthLargest.prune(kthLargest.top)
+
+	if kthLargest.topSize > 0 && newEntry.greater(kthLargest.top.top()) {
+		kthLargest.pushTo(kthLargest.top, newEntry)
+	} else {
+		kthLargest.pushTo(kthLargest.rest, newEntry)
+	}
+
+	kthLargest.rebalance()
+
+	if len(kthLargest.window) < kthLargest.windowSize {
+		return 0, false
+	}
+
+	kthLargest.prune(kthLargest.top)
+
+	return kthLargest.top.top().value, true
+}
+
+func (kthLargest *KthLargest) remove(old entry) {
+	kthLargest.deleted[old.seq] = struct{}{}
+
+	if kthLargest.sides[old.seq] == sideTop {
+		kthLargest.topSize--
+	} else {
+		kthLargest.restSize--
+	}
+
+	delete(kthLargest.sides, old.seq)
+	kthLargest.rebalance()
+	kthLargest.compact(kthLargest.top, kthLargest.topSize)
+	kthLargest.compact(kthLargest.rest, kthLargest.restSize)
+}
+
+// compact rebuilds the heap without its deleted entries once they outnumber the
+// live ones, so memory follows the window size rather than the stream length.
+func (kthLargest *KthLargest) compact(target *entryHeap, liveSize int) {
+	const minCompactLen = 16
+
+	if target.Len() <= minCompactLen || target.Len() <= 2*liveSize {
+		return
+	}
+
+	live := target.entries[:0]
+
+	for _, current := range target.entries {
+		_, isDeleted := kthLargest.deleted[current.seq]
+		if isDeleted {
+			delete(kthLargest.deleted, current.seq)
+
+			continue
+		}
+
+		live = append(live, current)
+	}
+
+	target.entries = live
+	heap.Init(target)
+}
+
+func (kthLargest *KthLargest) rebalance() {
+	for kthLargest.topSize > kthLargest.kth {
+		kthLargest.prune(kthLargest.top)
+		kthLargest.pushTo(kthLargest.rest, kthLargest.popFrom(kthLargest.top))
+	}
+
+	for kthLargest.topSize < kthLargest.kth && kthLargest.restSize > 0 {
+		kthLargest.prune(kthLargest.rest)
+		kthLargest.pushTo(kthLargest.top, kthLargest.popFrom(kthLargest.rest))
+	}
+}
+
+func (kthLargest *KthLargest) pushTo(target *entryHeap, newEntry entry) {
+	heap.Push(target, newEntry)
+
+	if target == kthLargest.top {
+		kthLargest.sides[newEntry.seq] = sideTop
+		kthLargest.topSize++
+	} else {
+		kthLargest.sides[newEntry.seq] = sideRest
+		kthLargest.restSize++
+	}
+}
+
+func (kthLargest *KthLargest) popFrom(source *entryHeap) entry {
+	popped, _ := heap.Pop(source).(entry)
+
+	if source == kthLargest.top {
+		kthLargest.topSize--
+	} else {
+		kthLargest.restSize--
+	}
+
+	return popped
+}
+
+func (kthLargest *KthLargest) prune(target *entryHeap) {
+	for target.Len() > 0 {
+		_, isDeleted := kthLargest.deleted[target.top().seq]
+		if !isDeleted {
+			return
+		}
+
+		delete(kthLargest.deleted, target.top().seq)
+		heap.Pop(target)
+	}
+}
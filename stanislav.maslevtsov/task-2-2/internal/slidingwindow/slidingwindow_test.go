@@ -0,0 +1,180 @@
+package slidingwindow_test
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/jambii1/task-2-2/internal/slidingwindow"
+)
+
+// minCompactLen mirrors the heap length below which compact leaves a heap alone.
+const minCompactLen = 16
+
+// oracle sorts the window on every push.
+type oracle struct {
+	kth        int
+	windowSize int
+	window     []int
+}
+
+func (window *oracle) push(value int) (int, bool) {
+	if len(window.window) == window.windowSize {
+		window.window = window.window[1:]
+	}
+
+	window.window = append(window.window, value)
+
+	if len(window.window) < window.windowSize {
+		return 0, false
+	}
+
+	sorted := slices.Clone(window.window)
+	slices.Sort(sorted)
+
+	return sorted[len(sorted)-window.kth], true
+}
+
+func checkStream(t *testing.T, name string, kth, windowSize int, values []int) *slidingwindow.KthLargest {
+	t.Helper()
+
+	kthLargest, err := slidingwindow.NewKthLargest(kth, windowSize)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	want := &oracle{kth: kth, windowSize: windowSize, window: nil}
+
+	for index, value := range values {
+		got, gotOK := kthLargest.Push(value)
+		wantValue, wantOK := want.push(value)
+
+		if got != wantValue || gotOK != wantOK {
+			t.Fatalf("%s: push %d (#%d): got %d, %t, want %d, %t", name, value, index, got, gotOK, wantValue, wantOK)
+		}
+	}
+
+	return kthLargest
+}
+
+func ascending(count int) []int {
+	values := make([]int, 0, count)
+	for value := range count {
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func descending(count int) []int {
+	values := ascending(count)
+	slices.Reverse(values)
+
+	return values
+}
+
+func TestNewKthLargest(t *testing.T) {
+	tests := []struct {
+		name       string
+		kth        int
+		windowSize int
+		wantErr    error
+	}{
+		{name: "zero kth", kth: 0, windowSize: 3, wantErr: slidingwindow.ErrInvalidKth},
+		{name: "negative kth", kth: -1, windowSize: 3, wantErr: slidingwindow.ErrInvalidKth},
+		{name: "window below kth", kth: 4, windowSize: 3, wantErr: slidingwindow.ErrInvalidWindow},
+		{name: "window equal to kth", kth: 3, windowSize: 3, wantErr: nil},
+	}
+
+	for _, test := range tests {
+		_, err := slidingwindow.NewKthLargest(test.kth, test.windowSize)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestPush(t *testing.T) {
+	tests := []struct {
+		name       string
+		kth        int
+		windowSize int
+		values     []int
+	}{
+		{name: "window of one", kth: 1, windowSize: 1, values: []int{5, 3, 9, 1}},
+		{name: "largest expires", kth: 1, windowSize: 3, values: []int{9, 1, 2, 3, 4, 0, 0, 0}},
+		{name: "kth expires from the top", kth: 2, windowSize: 3, values: []int{9, 8, 1, 1, 1, 7, 6, 5}},
+		{name: "kth equal to window", kth: 4, windowSize: 4, values: []int{4, 1, 3, 2, 8, 0, 5}},
+		{name: "duplicates", kth: 2, windowSize: 4, values: []int{5, 5, 5, 1, 5, 1, 1, 1, 5, 5}},
+		{name: "all equal", kth: 3, windowSize: 5, values: []int{7, 7, 7, 7, 7, 7, 7, 7}},
+		{name: "negative", kth: 2, windowSize: 3, values: []int{-5, -1, -9, -1, -3, 0, -7}},
+		{name: "shorter than window", kth: 1, windowSize: 5, values: []int{1, 2, 3}},
+		{name: "ascending", kth: 2, windowSize: 5, values: ascending(100)},
+		{name: "descending", kth: 2, windowSize: 5, values: descending(100)},
+	}
+
+	for _, test := range tests {
+		checkStream(t, test.name, test.kth, test.windowSize, test.values)
+	}
+}
+
+func TestPushRandom(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+
+	for round := range 200 {
+		windowSize := 1 + random.IntN(40)
+		kth := 1 + random.IntN(windowSize)
+
+		values := make([]int, 1+random.IntN(300))
+		for index := range values {
+			values[index] = random.IntN(10)
+		}
+
+		checkStream(t, "random", kth, windowSize, values)
+
+		if t.Failed() {
+			t.Fatalf("round %d: k %d, w %d", round, kth, windowSize)
+		}
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		kth        int
+		windowSize int
+		values     []int
+	}{
+		{name: "ascending buries expired values", kth: 1, windowSize: 4, values: ascending(1000)},
+		{name: "descending buries expired values", kth: 4, windowSize: 4, values: descending(1000)},
+		{name: "window above threshold", kth: 3, windowSize: 40, values: ascending(1000)},
+	}
+
+	for _, test := range tests {
+		kthLargest, err := slidingwindow.NewKthLargest(test.kth, test.windowSize)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		maxHeld := 0
+
+		for _, value := range test.values {
+			kthLargest.Push(value)
+
+			top, rest, liveTop, liveRest := kthLargest.HeapLens()
+
+			// A push adds one entry after the compaction, and the rebalance can
+			// move one more into either heap.
+			if top > max(minCompactLen, 2*liveTop)+2 || rest > max(minCompactLen, 2*liveRest)+2 {
+				t.Fatalf("%s: heaps hold %d and %d entries for %d and %d live", test.name, top, rest, liveTop, liveRest)
+			}
+
+			maxHeld = max(maxHeld, top+rest)
+		}
+
+		if maxHeld <= test.windowSize {
+			t.Errorf("%s: heaps never held an expired entry, deletion is not lazy", test.name)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jambii1/task-3/internal/atomicfile"
+	"github.com/jambii1/task-3/internal/chart"
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/query"
+	"github.com/jambii1/task-3/internal/source"
+	"github.com/jambii1/task-3/internal/validate"
+)
+
+func runChart(ctx context.Context, conRec *config.ConfigRecord, rateSource source.RateSource, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: chart <bars|lines>", errWrongArguments)
+	}
+
+	switch args[0] {
+	case "bars":
+		return runChartBars(ctx, conRec, rateSource, args[1:])
+	case "lines":
+		return runChartLines(ctx, conRec, args[1:])
+	default:
+		return fmt.Errorf("%w: chart %q", errUnknownCommand, args[0])
+	}
+}
+
+// runChartBars charts the unit rates of the latest snapshot after the query, so
+// the bars follow the configured filter and sort.
+func runChartBars(ctx context.Context, conRec *config.ConfigRecord, rateSource source.RateSource, args []string) error {
+	flagSet := flag.NewFlagSet("chart bars", flag.ContinueOnError)
+
+	paths, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) > 1 {
+		return fmt.Errorf("%w: chart bars [output.svg]", errWrongArguments)
+	}
+
+	currencies, _, err := prepareRates(ctx, conRec, rateSource)
+	if err != nil {
+		return err
+	}
+
+	bars := make([]chart.Bar, 0, len(currencies.Data))
+
+	for _, cur := range currencies.Data {
+		rate, err := cur.UnitRate()
+		if err != nil {
+			return failure.Decode(fmt.Errorf("failed to chart %s: %w", cur.CharCode, err))
+		}
+
+		bars = append(bars, chart.Bar{Label: cur.CharCode, Value: rate.Float64()})
+	}
+
+	if len(bars) == 0 {
+		return failure.Config(fmt.Errorf("failed to chart rates: %w", chart.ErrNoData))
+	}
+
+	title := "Rates on " + currencies.Date + " in " + baseOf(currencies)
+
+	return writeChart(conRec, paths, func(writer io.Writer) error {
+		return chart.WriteBars(writer, title, bars)
+	})
+}
+
+// runChartLines charts the unit rate of every currency the query keeps across
+// the daily files of a directory, one svg named after the char code each.
+func runChartLines(ctx context.Context, conRec *config.ConfigRecord, args []string) error {
+	flagSet := flag.NewFlagSet("chart lines", flag.ContinueOnError)
+
+	defaultDir := ""
+	if conRec.Source.Type == source.TypeDirectory {
+		defaultDir = conRec.Source.Path
+	}
+
+	dir := flagSet.String("dir", defaultDir, "directory of daily xml files, the directory source when empty")
+	codes := flagSet.String("codes", "", "comma separated char codes, those kept by the query when empty")
+
+	paths, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) != 1 || *dir == "" {
+		return fmt.Errorf("%w: chart lines <output-dir> [-dir snapshots] [-codes USD,EUR]", errWrongArguments)
+	}
+
+	queryRecord := conRec.Query
+	if *codes != "" {
+		queryRecord.Allow = strings.Split(*codes, ",")
+	}
+
+	currencyQuery, err := query.New(queryRecord)
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to build query: %w", err))
+	}
+
+	decode, err := validate.Decoder(conRec.Validation, conRec.InputEncoding, logIssue)
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to create decoder: %w", err))
+	}
+
+	snapshots, err := source.NewDirectory(*dir, source.WithDecoder(decode)).Snapshots(ctx)
+	if err != nil {
+		return fetchFailure(fmt.Errorf("failed to read snapshots: %w", err))
+	}
+
+	series, err := collectSeries(conRec, currencyQuery, snapshots)
+	if err != nil {
+		return err
+	}
+
+	if len(series) == 0 {
+		return failure.Input(fmt.Errorf("failed to chart %s: %w", *dir, chart.ErrNoData))
+	}
+
+	for _, line := range series {
+		output := filepath.Join(paths[0], line.Name+".svg")
+		title := line.Name + " rate in " + baseOf(snapshots[0].Currencies)
+
+		err = writeChart(conRec, []string{output}, func(writer io.Writer) error {
+			return chart.WriteLines(writer, title, currency.DateLayout, []chart.Series{line})
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(output)
+	}
+
+	return nil
+}
+
+func collectSeries(
+	conRec *config.ConfigRecord,
+	currencyQuery *query.Query,
+	snapshots []source.Snapshot,
+) ([]chart.Series, error) {
+	byCode := make(map[string]*chart.Series)
+
+	for _, snapshot := range snapshots {
+		currencies := snapshot.Currencies
+
+		if conRec.BaseCurrency != "" {
+			err := currencies.Rebase(conRec.BaseCurrency)
+			if err != nil {
+				return nil, failure.Config(fmt.Errorf("failed to rebase %s: %w", snapshot.Path, err))
+			}
+		}
+
+		for _, cur := range currencies.Data {
+			ok, err := currencyQuery.Match(cur)
+			if err != nil {
+				return nil, failure.Config(fmt.Errorf("failed to apply query: %w", err))
+			}
+
+			if !ok {
+				continue
+			}
+
+			rate, err := cur.UnitRate()
+			if err != nil {
+				return nil, failure.Decode(fmt.Errorf("failed to chart %s of %s: %w", cur.CharCode, snapshot.Path, err))
+			}
+
+			code := strings.ToUpper(cur.CharCode)
+			if byCode[code] == nil {
+				byCode[code] = &chart.Series{Name: code, Points: nil}
+			}
+
+			byCode[code].Points = append(byCode[code].Points, chart.Point{Date: snapshot.Date, Value: rate.Float64()})
+		}
+	}
+
+	series := make([]chart.Series, 0, len(byCode))
+	for _, line := range byCode {
+		series = append(series, *line)
+	}
+
+	sort.Slice(series, func(left, right int) bool {
+		return series[left].Name < series[right].Name
+	})
+
+	return series, nil
+}
+
+func baseOf(currencies *currency.Currencies) string {
+	if currencies.QuotedIn != "" {
+		return currencies.QuotedIn
+	}
+
+	return currency.BaseCharCode
+}
+
+// writeChart writes to the output path the way generate writes its output, or
+// to stdout when no path is given.
+func writeChart(conRec *config.ConfigRecord, paths []string, write func(writer io.Writer) error) error {
+	if len(paths) == 0 {
+		return failure.Output(write(os.Stdout))
+	}
+
+	opts, err := outputOptions(conRec)
+	if err != nil {
+		return failure.Config(err)
+	}
+
+	err = atomicfile.Write(paths[0], write, opts...)
+	if err != nil {
+		return failure.Output(fmt.Errorf("failed to write chart: %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/feed"
+	"github.com/jambii1/task-3/internal/source"
+)
+
+var (
+	errUnknownCommand  = errors.New("unknown command")
+	errWrongArguments  = errors.New("wrong number of arguments")
+	errUnknownFormat   = errors.New("unknown output format")
+	errInvalidInterval = errors.New("interval must be positive")
+)
+
+func runCommand(
+	ctx context.Context,
+	conRec *config.ConfigRecord,
+	rateSource source.RateSource,
+	name string,
+	args []string,
+) error {
+	var err error
+
+	switch name {
+	case "convert":
+		err = runConvert(ctx, rateSource, args)
+	case "history":
+		err = runHistory(ctx, conRec, rateSource, args)
+	case "validate":
+		err = runValidate(conRec, args)
+	case "serve":
+		err = runServe(ctx, conRec, rateSource, args)
+	case "diff":
+		err = runDiff(ctx, conRec, rateSource, args)
+	case "chart":
+		err = runChart(ctx, conRec, rateSource, args)
+	case "encode":
+		err = runEncode(conRec, args)
+	default:
+		err = fmt.Errorf("%w: %q", errUnknownCommand, name)
+	}
+
+	if errors.Is(err, errUnknownCommand) || errors.Is(err, errWrongArguments) || errors.Is(err, errUnknownFormat) {
+		return failure.Usage(err)
+	}
+
+	return err
+}
+
+// parseArgs lets flags follow positional arguments, as in "convert 100 USD EUR --date 02.03.2002".
+func parseArgs(flagSet *flag.FlagSet, args []string) ([]string, error) {
+	flagSet.SetOutput(io.Discard)
+
+	var positional []string
+
+	for {
+		err := flagSet.Parse(args)
+		if err != nil {
+			return nil, failure.Usage(fmt.Errorf("failed to parse %s arguments: %w", flagSet.Name(), err))
+		}
+
+		if flagSet.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, flagSet.Arg(0))
+		args = flagSet.Args()[1:]
+	}
+}
+
+func parseDate(rawDate string) (time.Time, error) {
+	if rawDate == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(currency.DateLayout, rawDate)
+	if err != nil {
+		return time.Time{}, failure.Usage(fmt.Errorf("failed to parse date: %w", err))
+	}
+
+	return date, nil
+}
+
+// fetchFailure classifies a failed fetch: input a feed could not decode is a
+// decode error, input that could not be read an input one.
+func fetchFailure(err error) error {
+	if errors.Is(err, feed.ErrMalformed) {
+		return failure.Decode(err)
+	}
+
+	return failure.Input(err)
+}
+
+func printJSON(value any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode to json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/failure"
+)
+
+// runConfig handles "config print", which shows the effective config and the
+// layer every value came from.
+func runConfig(origins []config.Origin, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return failure.Usage(fmt.Errorf("%w: config print [-format text|json]", errWrongArguments))
+	}
+
+	flagSet := flag.NewFlagSet("config print", flag.ContinueOnError)
+	outputFormat := flagSet.String("format", "text", "output format: text or json")
+
+	_, err := parseArgs(flagSet, args[1:])
+	if err != nil {
+		return err
+	}
+
+	switch *outputFormat {
+	case "json":
+		return printJSON(origins)
+	case "text":
+		return printOrigins(origins)
+	default:
+		return failure.Usage(fmt.Errorf("%w: %q", errUnknownFormat, *outputFormat))
+	}
+}
+
+func printOrigins(origins []config.Origin) error {
+	const padding = 2
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, padding, ' ', 0)
+
+	for _, origin := range origins {
+		_, err := fmt.Fprintf(writer, "%s\t%s\t%s\n", origin.Key, origin.Value, origin.Source)
+		if err != nil {
+			return failure.Output(fmt.Errorf("failed to print config: %w", err))
+		}
+	}
+
+	err := writer.Flush()
+	if err != nil {
+		return failure.Output(fmt.Errorf("failed to print config: %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/source"
+)
+
+func runConvert(ctx context.Context, rateSource source.RateSource, args []string) error {
+	const (
+		convertArgsAmount = 3
+		defaultPrecision  = 4
+	)
+
+	flagSet := flag.NewFlagSet("convert", flag.ContinueOnError)
+	rawDate := flagSet.String("date", "", "rates date in DD.MM.YYYY format, latest if empty")
+	precision := flagSet.Uint("precision", defaultPrecision, "digits after the decimal separator")
+
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != convertArgsAmount {
+		return fmt.Errorf("%w: convert <amount> <from> <to>", errWrongArguments)
+	}
+
+	amount, err := decimal.Parse(positional[0])
+	if err != nil {
+		return failure.Usage(fmt.Errorf("failed to parse amount: %w", err))
+	}
+
+	date, err := parseDate(*rawDate)
+	if err != nil {
+		return err
+	}
+
+	currencies, err := rateSource.Fetch(ctx, date)
+	if err != nil {
+		return fetchFailure(fmt.Errorf("failed to fetch rates: %w", err))
+	}
+
+	result, err := currency.Convert(currencies, amount, positional[1], positional[2], int32(*precision))
+	if err != nil {
+		return failure.Usage(fmt.Errorf("failed to convert: %w", err))
+	}
+
+	fmt.Println(result)
+
+	return nil
+}
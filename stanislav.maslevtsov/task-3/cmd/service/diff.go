@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/diff"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/source"
+	"github.com/jambii1/task-3/internal/validate"
+)
+
+// runDiff compares two xml files, or two dates of the configured source when the
+// files are left out.
+func runDiff(ctx context.Context, conRec *config.ConfigRecord, rateSource source.RateSource, args []string) error {
+	const diffFilesAmount = 2
+
+	flagSet := flag.NewFlagSet("diff", flag.ContinueOnError)
+	rawFrom := flagSet.String("from", "", "older rates date in DD.MM.YYYY format")
+	rawTo := flagSet.String("to", "", "newer rates date in DD.MM.YYYY format, latest if empty")
+	order := flagSet.String("sort", diff.SortCode, "order of changes: code or movers")
+	outputFormat := flagSet.String("format", "json", "output format: json or csv")
+
+	paths, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	var older, newer *currency.Currencies
+
+	switch len(paths) {
+	case diffFilesAmount:
+		older, newer, err = fetchFiles(ctx, conRec, paths[0], paths[1])
+	case 0:
+		older, newer, err = fetchDates(ctx, rateSource, *rawFrom, *rawTo)
+	default:
+		return fmt.Errorf("%w: diff [<old.xml> <new.xml>] [-from date] [-to date]", errWrongArguments)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	report, err := diff.Compare(older, newer)
+	if err != nil {
+		return failure.Decode(fmt.Errorf("failed to compare rates: %w", err))
+	}
+
+	err = report.Sort(*order)
+	if err != nil {
+		return failure.Usage(fmt.Errorf("failed to sort changes: %w", err))
+	}
+
+	switch *outputFormat {
+	case "json":
+		return printJSON(report)
+	case "csv":
+		return failure.Output(report.WriteCSV(os.Stdout))
+	default:
+		return fmt.Errorf("%w: %q", errUnknownFormat, *outputFormat)
+	}
+}
+
+func fetchFiles(
+	ctx context.Context,
+	conRec *config.ConfigRecord,
+	olderPath, newerPath string,
+) (*currency.Currencies, *currency.Currencies, error) {
+	decode, err := validate.Decoder(conRec.Validation, conRec.InputEncoding, logIssue)
+	if err != nil {
+		return nil, nil, failure.Config(fmt.Errorf("failed to create decoder: %w", err))
+	}
+
+	older, err := source.NewFile(olderPath, source.WithDecoder(decode)).Fetch(ctx, time.Time{})
+	if err != nil {
+		return nil, nil, fetchFailure(fmt.Errorf("failed to read older rates: %w", err))
+	}
+
+	newer, err := source.NewFile(newerPath, source.WithDecoder(decode)).Fetch(ctx, time.Time{})
+	if err != nil {
+		return nil, nil, fetchFailure(fmt.Errorf("failed to read newer rates: %w", err))
+	}
+
+	return older, newer, nil
+}
+
+func fetchDates(
+	ctx context.Context,
+	rateSource source.RateSource,
+	rawFrom, rawTo string,
+) (*currency.Currencies, *currency.Currencies, error) {
+	if rawFrom == "" {
+		return nil, nil, fmt.Errorf("%w: diff needs two files or -from", errWrongArguments)
+	}
+
+	from, err := parseDate(rawFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	to, err := parseDate(rawTo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	older, err := rateSource.Fetch(ctx, from)
+	if err != nil {
+		return nil, nil, fetchFailure(fmt.Errorf("failed to fetch older rates: %w", err))
+	}
+
+	newer, err := rateSource.Fetch(ctx, to)
+	if err != nil {
+		return nil, nil, fetchFailure(fmt.Errorf("failed to fetch newer rates: %w", err))
+	}
+
+	return older, newer, nil
+}
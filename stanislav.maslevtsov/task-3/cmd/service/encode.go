@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/format"
+)
+
+// runEncode turns json rates back into a CBR xml file. Records without a nominal
+// or a name, like the default output, take them from the reference xml. The date
+// and name flags override those of the json and the reference.
+func runEncode(conRec *config.ConfigRecord, args []string) error {
+	const (
+		maxEncodeArgsAmount = 2
+		defaultValCursName  = "Foreign Currency Market"
+	)
+
+	flagSet := flag.NewFlagSet("encode", flag.ContinueOnError)
+	outputCharset := flagSet.String("charset", conRec.OutputCharset, "output charset, windows-1251 when empty")
+	rawDate := flagSet.String("date", "", "ValCurs date in DD.MM.YYYY format")
+	name := flagSet.String("name", "", "ValCurs name attribute, "+defaultValCursName+" when empty")
+	referencePath := flagSet.String("reference", "", "ValCurs xml the records take missing nominals and names from")
+
+	paths, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 || len(paths) > maxEncodeArgsAmount {
+		return fmt.Errorf(
+			"%w: encode <input.json> [output.xml] [-reference input.xml] [-charset name] [-date date] [-name name]",
+			errWrongArguments,
+		)
+	}
+
+	var reference *currency.Currencies
+
+	if *referencePath != "" {
+		reference, err = decodeXMLFile(*referencePath, conRec.InputEncoding)
+		if err != nil {
+			return err
+		}
+	}
+
+	currencies, err := decodeJSONFile(paths[0], reference)
+	if err != nil && reference == nil && errors.Is(err, currency.ErrIncompleteRecord) {
+		return fmt.Errorf("%w, pass -reference", err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if *rawDate != "" {
+		date, err := parseDate(*rawDate)
+		if err != nil {
+			return err
+		}
+
+		currencies.Date = date.Format(currency.DateLayout)
+	}
+
+	if *name != "" {
+		currencies.Name = *name
+	}
+
+	if currencies.Date == "" {
+		return fmt.Errorf("%w: json records have no date, pass -date", errWrongArguments)
+	}
+
+	if currencies.Name == "" {
+		currencies.Name = defaultValCursName
+	}
+
+	encoder, err := format.WithCharset(format.CBR{Charset: ""}, *outputCharset)
+	if err != nil {
+		return failure.Usage(fmt.Errorf("failed to resolve output charset: %w", err))
+	}
+
+	if len(paths) == 1 {
+		return failure.Output(encoder.Encode(os.Stdout, currencies))
+	}
+
+	opts, err := outputOptions(conRec)
+	if err != nil {
+		return failure.Config(err)
+	}
+
+	err = currency.Write(paths[1], currencies, encoder, opts...)
+	if err != nil {
+		return failure.Output(fmt.Errorf("failed to write output: %w", err))
+	}
+
+	return nil
+}
+
+func decodeJSONFile(path string, reference *currency.Currencies) (*currency.Currencies, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, failure.Input(fmt.Errorf("failed to open json currencies file: %w", err))
+	}
+
+	currencies, err := currency.DecodeJSON(file, reference)
+	closeErr := file.Close()
+
+	switch {
+	case err != nil:
+		return nil, failure.Decode(err)
+	case closeErr != nil:
+		return nil, failure.Input(fmt.Errorf("failed to close json currencies file: %w", closeErr))
+	}
+
+	return currencies, nil
+}
+
+func decodeXMLFile(path, encoding string) (*currency.Currencies, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, failure.Input(fmt.Errorf("failed to open xml currencies file: %w", err))
+	}
+
+	currencies, err := currency.DecodeCharset(file, encoding)
+	closeErr := file.Close()
+
+	switch {
+	case err != nil:
+		return nil, failure.Decode(err)
+	case closeErr != nil:
+		return nil, failure.Input(fmt.Errorf("failed to close xml currencies file: %w", closeErr))
+	}
+
+	return currencies, nil
+}
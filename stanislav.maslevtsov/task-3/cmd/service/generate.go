@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jambii1/task-3/internal/atomicfile"
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/format"
+	"github.com/jambii1/task-3/internal/query"
+	"github.com/jambii1/task-3/internal/source"
+)
+
+func generate(ctx context.Context, conRec *config.ConfigRecord, rateSource source.RateSource) error {
+	currencies, currencyQuery, err := prepareRates(ctx, conRec, rateSource)
+	if err != nil {
+		return err
+	}
+
+	if currencies.Source != "" {
+		logger.Info(
+			"read the input as the "+currencies.Source+" feed",
+			"source", currencies.Source, "output", conRec.OutputFile,
+		)
+	}
+
+	if currencies.Encoding != "" {
+		logger.Info(
+			"detected input encoding "+currencies.Encoding,
+			"encoding", currencies.Encoding, "output", conRec.OutputFile,
+		)
+	}
+
+	encoder, err := format.Resolve(conRec.OutputFormat, conRec.OutputFile)
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to resolve output format: %w", err))
+	}
+
+	encoder, err = format.WithFields(encoder, currencyQuery.Fields)
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to resolve output format: %w", err))
+	}
+
+	encoder, err = format.WithCharset(encoder, conRec.OutputCharset)
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to resolve output format: %w", err))
+	}
+
+	opts, err := outputOptions(conRec)
+	if err != nil {
+		return failure.Config(err)
+	}
+
+	err = currency.Write(conRec.OutputFile, currencies, encoder, opts...)
+	if err != nil {
+		return failure.Output(fmt.Errorf("failed to write output: %w", err))
+	}
+
+	return nil
+}
+
+// prepareRates fetches the latest snapshot, rebases it and applies the query.
+func prepareRates(
+	ctx context.Context,
+	conRec *config.ConfigRecord,
+	rateSource source.RateSource,
+) (*currency.Currencies, *query.Query, error) {
+	currencies, err := rateSource.Fetch(ctx, time.Time{})
+	if err != nil {
+		return nil, nil, fetchFailure(fmt.Errorf("failed to fetch rates: %w", err))
+	}
+
+	if conRec.BaseCurrency != "" {
+		err = currencies.Rebase(conRec.BaseCurrency)
+		if err != nil {
+			return nil, nil, failure.Config(fmt.Errorf("failed to rebase rates: %w", err))
+		}
+	}
+
+	currencyQuery, err := query.New(conRec.Query)
+	if err != nil {
+		return nil, nil, failure.Config(fmt.Errorf("failed to build query: %w", err))
+	}
+
+	err = currencyQuery.Apply(currencies)
+	if err != nil {
+		return nil, nil, failure.Config(fmt.Errorf("failed to apply query: %w", err))
+	}
+
+	return currencies, currencyQuery, nil
+}
+
+func outputOptions(conRec *config.ConfigRecord) ([]atomicfile.Option, error) {
+	var opts []atomicfile.Option
+
+	if conRec.OutputMode != "" {
+		mode, err := strconv.ParseUint(conRec.OutputMode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse output permissions: %w", err)
+		}
+
+		opts = append(opts, atomicfile.WithFileMode(os.FileMode(mode)))
+	}
+
+	if conRec.OutputBackup {
+		opts = append(opts, atomicfile.WithBackup())
+	}
+
+	if conRec.NoOverwrite {
+		opts = append(opts, atomicfile.WithNoOverwrite())
+	}
+
+	return opts, nil
+}
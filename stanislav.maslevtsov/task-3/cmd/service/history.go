@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/history"
+	"github.com/jambii1/task-3/internal/source"
+)
+
+func runHistory(
+	ctx context.Context,
+	conRec *config.ConfigRecord,
+	rateSource source.RateSource,
+	args []string,
+) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: history <ingest|series|stats|change>", errWrongArguments)
+	}
+
+	store, err := history.Open(conRec.HistoryFile)
+	if err != nil {
+		return failure.Input(fmt.Errorf("failed to open history: %w", err))
+	}
+
+	switch args[0] {
+	case "ingest":
+		return runHistoryIngest(ctx, conRec, store, rateSource, args[1:])
+	case "series", "stats", "change":
+		return runHistoryQuery(store, args[0], args[1:])
+	default:
+		return fmt.Errorf("%w: history %q", errUnknownCommand, args[0])
+	}
+}
+
+func runHistoryIngest(
+	ctx context.Context,
+	conRec *config.ConfigRecord,
+	store *history.Store,
+	rateSource source.RateSource,
+	args []string,
+) error {
+	flagSet := flag.NewFlagSet("history ingest", flag.ContinueOnError)
+	force := flagSet.Bool("force", false, "ingest dates that are already stored")
+	encoding := flagSet.String("encoding", conRec.InputEncoding, "input encoding, detected when empty")
+
+	paths, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return ingestFromSource(ctx, store, rateSource, *force)
+	}
+
+	files, err := collectXMLFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		err = ingestFile(store, path, *encoding, *force)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ingestFromSource(ctx context.Context, store *history.Store, rateSource source.RateSource, force bool) error {
+	currencies, err := rateSource.Fetch(ctx, time.Time{})
+	if err != nil {
+		return fetchFailure(fmt.Errorf("failed to fetch rates: %w", err))
+	}
+
+	date, err := currencies.ParseDate()
+	if err != nil {
+		return fmt.Errorf("failed to ingest rates: %w", err)
+	}
+
+	if store.Has(date) && !force {
+		fmt.Printf("skipped %s\n", currencies.Date)
+
+		return nil
+	}
+
+	err = store.Ingest(currencies)
+	if err != nil {
+		return failure.Output(fmt.Errorf("failed to ingest %s: %w", currencies.Date, err))
+	}
+
+	fmt.Printf("ingested %s\n", currencies.Date)
+
+	return nil
+}
+
+func ingestFile(store *history.Store, path, encoding string, force bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return failure.Input(fmt.Errorf("failed to open xml currencies file: %w", err))
+	}
+
+	dates, err := store.IngestReader(file, encoding, force)
+	for _, date := range dates {
+		fmt.Printf("ingested %s from %s\n", date, path)
+	}
+
+	closeErr := file.Close()
+
+	switch {
+	case errors.Is(err, history.ErrWrite):
+		return failure.Output(fmt.Errorf("failed to ingest %s: %w", path, err))
+	case err != nil:
+		return failure.Decode(fmt.Errorf("failed to ingest %s: %w", path, err))
+	case closeErr != nil:
+		return failure.Input(fmt.Errorf("failed to close xml currencies file: %w", closeErr))
+	}
+
+	return nil
+}
+
+func collectXMLFiles(paths []string) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, failure.Input(fmt.Errorf("failed to get file info: %w", err))
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, failure.Input(fmt.Errorf("failed to read directory: %w", err))
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".xml") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func runHistoryQuery(store *history.Store, query string, args []string) error {
+	flagSet := flag.NewFlagSet("history "+query, flag.ContinueOnError)
+	rawFrom := flagSet.String("from", "", "first date in DD.MM.YYYY format, inclusive")
+	rawTo := flagSet.String("to", "", "last date in DD.MM.YYYY format, inclusive")
+
+	positional, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		return fmt.Errorf("%w: history %s <char-code>", errWrongArguments, query)
+	}
+
+	from, err := parseDate(*rawFrom)
+	if err != nil {
+		return err
+	}
+
+	to, err := parseDate(*rawTo)
+	if err != nil {
+		return err
+	}
+
+	switch query {
+	case "series":
+		return printJSON(store.Series(positional[0], from, to))
+	case "stats":
+		stats, err := store.Stats(positional[0], from, to)
+		if err != nil {
+			return fmt.Errorf("failed to query history: %w", err)
+		}
+
+		return printJSON(stats)
+	default:
+		return printJSON(store.Changes(positional[0], from, to))
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/source"
+	"github.com/jambii1/task-3/internal/validate"
+)
+
+var errJobsFailed = errors.New("jobs failed")
+
+type jobResult struct {
+	name     string
+	output   string
+	duration time.Duration
+	err      error
+}
+
+// runJobs runs every configured job with at most conRec.Workers at a time.
+// A failed job is reported in the summary and does not stop the others.
+// A non-empty validation mode from the command line overrides the jobs' own.
+func runJobs(ctx context.Context, conRec *config.ConfigRecord, validation string) error {
+	workers := conRec.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var (
+		results   = make([]jobResult, len(conRec.Jobs))
+		semaphore = make(chan struct{}, workers)
+		waitGroup sync.WaitGroup
+	)
+
+	for index := range conRec.Jobs {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			jobConfig := conRec.Job(index)
+			if validation != "" {
+				jobConfig.Validation = validation
+			}
+
+			started := time.Now()
+			err := runJob(ctx, jobConfig)
+
+			results[index] = jobResult{
+				name:     conRec.JobName(index),
+				output:   jobConfig.OutputFile,
+				duration: time.Since(started),
+				err:      err,
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	var (
+		failed   = 0
+		firstErr error
+	)
+
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+
+			if firstErr == nil {
+				firstErr = result.err
+			}
+
+			logger.Error(
+				fmt.Sprintf("%s: failed after %s: %v", result.name, result.duration.Round(time.Millisecond), result.err),
+				"job", result.name, "duration", result.duration, "error", result.err.Error(),
+				"kind", failure.Kind(result.err), "exit_code", failure.ExitCode(result.err),
+			)
+
+			continue
+		}
+
+		logger.Info(
+			fmt.Sprintf("%s: wrote %s in %s", result.name, result.output, result.duration.Round(time.Millisecond)),
+			"job", result.name, "output", result.output, "duration", result.duration,
+		)
+	}
+
+	logger.Info(
+		fmt.Sprintf("%d of %d jobs succeeded", len(results)-failed, len(results)),
+		"succeeded", len(results)-failed, "total", len(results),
+	)
+
+	if failed > 0 {
+		return classifyLike(fmt.Errorf("%w: %d of %d", errJobsFailed, failed, len(results)), firstErr)
+	}
+
+	return nil
+}
+
+// classifyLike gives err the class of the first failed job, so the exit code
+// tells what went wrong the way it does for a single run.
+func classifyLike(err, cause error) error {
+	switch failure.ExitCode(cause) {
+	case failure.ExitUsage:
+		return failure.Usage(err)
+	case failure.ExitConfig:
+		return failure.Config(err)
+	case failure.ExitInput:
+		return failure.Input(err)
+	case failure.ExitDecode:
+		return failure.Decode(err)
+	case failure.ExitOutput:
+		return failure.Output(err)
+	default:
+		return err
+	}
+}
+
+func runJob(ctx context.Context, jobConfig *config.ConfigRecord) error {
+	decode, err := validate.Decoder(jobConfig.Validation, jobConfig.InputEncoding, logIssue)
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to create decoder: %w", err))
+	}
+
+	rateSource, err := source.New(jobConfig, source.WithDecoder(decode))
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to create rate source: %w", err))
+	}
+
+	return generate(ctx, jobConfig, rateSource)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/validate"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var (
+	errUnknownLogFormat = errors.New("unknown log format")
+
+	logger = slog.New(&plainHandler{writer: os.Stderr, mutex: &sync.Mutex{}})
+)
+
+// plainHandler writes only the message, so the text diagnostics read as before
+// and the attributes are left for the json format.
+type plainHandler struct {
+	writer io.Writer
+	mutex  *sync.Mutex
+}
+
+func (*plainHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (handler *plainHandler) Handle(_ context.Context, record slog.Record) error {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	_, err := fmt.Fprintln(handler.writer, record.Message)
+	if err != nil {
+		return fmt.Errorf("failed to write log record: %w", err)
+	}
+
+	return nil
+}
+
+func (handler *plainHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return handler
+}
+
+func (handler *plainHandler) WithGroup(string) slog.Handler {
+	return handler
+}
+
+func setLogFormat(format string) error {
+	switch format {
+	case logFormatText:
+	case logFormatJSON:
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	default:
+		return failure.Usage(fmt.Errorf("%w: %q", errUnknownLogFormat, format))
+	}
+
+	return nil
+}
+
+func logError(err error) {
+	logger.Error(err.Error(), "kind", failure.Kind(err), "exit_code", failure.ExitCode(err))
+}
+
+func logIssue(issue validate.Issue) {
+	level := slog.LevelWarn
+	if issue.Severity == validate.SeverityError {
+		level = slog.LevelError
+	}
+
+	logger.Log(context.Background(), level, issue.String(), "issue", issue)
+}
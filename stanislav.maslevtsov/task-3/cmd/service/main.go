@@ -1,31 +1,106 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"slices"
+	"fmt"
+	"os"
+	"time"
 
 	"github.com/jambii1/task-3/internal/config"
-	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/source"
+	"github.com/jambii1/task-3/internal/validate"
 )
 
 func main() {
+	err := run()
+	if err != nil {
+		logError(err)
+	}
+
+	os.Exit(failure.ExitCode(err))
+}
+
+func run() error {
 	configPath := flag.String("config", "./config.yaml", "config path")
+	strict := flag.Bool("strict", false, "fail when the input has any validation error")
+	lenient := flag.Bool("lenient", false, "skip input records with validation errors")
+	watch := flag.Bool("watch", false, "regenerate the output whenever the input changes")
+	watchInterval := flag.Duration("watch-interval", time.Second, "how often the input is polled in watch mode")
+	watchDebounce := flag.Duration("watch-debounce", defaultDebounce, "how long the input must stay unchanged")
+	logFormat := flag.String("log-format", logFormatText, "stderr diagnostics format: text or json")
+	overrides := config.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
-	config, err := config.Parse(*configPath)
+	err := setLogFormat(*logFormat)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	loadOpts := []config.LoadOption{config.WithOverrides(overrides)}
+	if !isFlagSet("config") {
+		loadOpts = append(loadOpts, config.Optional())
 	}
 
-	currencies, err := currency.Parse(config.InputFile)
+	config, origins, err := config.Load(*configPath, loadOpts...)
 	if err != nil {
-		panic(err)
+		return failure.Config(err)
+	}
+
+	validation := ""
+
+	switch {
+	case *strict:
+		validation = validate.ModeStrict
+	case *lenient:
+		validation = validate.ModeLenient
+	}
+
+	if validation != "" {
+		config.Validation = validation
 	}
 
-	slices.SortFunc(currencies.Data, currency.Compare)
+	if flag.Arg(0) == "config" {
+		return runConfig(origins, flag.Args()[1:])
+	}
+
+	decode, err := validate.Decoder(config.Validation, config.InputEncoding, logIssue)
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to create decoder: %w", err))
+	}
 
-	err = currency.Write(config.OutputFile, currencies)
+	rateSource, err := source.New(config, source.WithDecoder(decode))
 	if err != nil {
-		panic(err)
+		return failure.Config(fmt.Errorf("failed to create rate source: %w", err))
+	}
+
+	if flag.NArg() > 0 {
+		return runCommand(context.Background(), config, rateSource, flag.Arg(0), flag.Args()[1:])
 	}
+
+	switch {
+	case len(config.Jobs) > 0 && *watch:
+		return failure.Usage(errWatchJobs)
+	case len(config.Jobs) > 0:
+		return runJobs(context.Background(), config, validation)
+	case *watch:
+		return withMetrics(context.Background(), config, func(ctx context.Context) error {
+			return runWatch(ctx, config, rateSource, *watchInterval, *watchDebounce)
+		})
+	default:
+		return generate(context.Background(), config, rateSource)
+	}
+}
+
+func isFlagSet(name string) bool {
+	isSet := false
+
+	flag.Visit(func(visited *flag.Flag) {
+		if visited.Name == name {
+			isSet = true
+		}
+	})
+
+	return isSet
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/metrics"
+	"github.com/jambii1/task-3/internal/source"
+	"github.com/jambii1/task-3/internal/validate"
+)
+
+// withMetrics runs a long-running command and, when metrics.addr is set, serves
+// /metrics beside it. The listener reloads the configured input on every
+// interval through a decoder of its own, so validation issues are counted as
+// well as logged. A listener that fails to start stops the command.
+func withMetrics(ctx context.Context, conRec *config.ConfigRecord, run func(ctx context.Context) error) error {
+	if conRec.Metrics.Addr == "" {
+		return run(ctx)
+	}
+
+	if conRec.Metrics.Interval <= 0 {
+		return failure.Usage(fmt.Errorf("%w: %s", errInvalidInterval, conRec.Metrics.Interval))
+	}
+
+	collector := metrics.New()
+
+	decode, err := validate.Decoder(conRec.Validation, conRec.InputEncoding, func(issue validate.Issue) {
+		logIssue(issue)
+		collector.ObserveIssue(issue)
+	})
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to create decoder: %w", err))
+	}
+
+	rateSource, err := source.New(conRec, source.WithDecoder(decode))
+	if err != nil {
+		return failure.Config(fmt.Errorf("failed to create rate source: %w", err))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	listenErr := make(chan error, 1)
+
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", collector)
+
+		err := listen(ctx, conRec.Metrics.Addr, mux)
+		if err != nil {
+			cancel()
+		}
+
+		listenErr <- err
+	}()
+
+	go refreshMetrics(ctx, collector, rateSource, conRec.Metrics.Interval)
+
+	err = run(ctx)
+
+	cancel()
+
+	metricsErr := <-listenErr
+
+	switch {
+	case err != nil:
+		return err
+	case metricsErr != nil:
+		return fmt.Errorf("failed to serve metrics: %w", metricsErr)
+	}
+
+	return nil
+}
+
+func refreshMetrics(ctx context.Context, collector *metrics.Collector, rateSource source.RateSource, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := collector.Refresh(ctx, rateSource)
+		if err != nil {
+			err = fetchFailure(err)
+			collector.ObserveLoadError(failure.Kind(err))
+
+			logger.Error(
+				"metrics: keeping previous rates: "+err.Error(),
+				"error", err.Error(), "kind", failure.Kind(err),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
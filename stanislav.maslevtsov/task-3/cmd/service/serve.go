@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/server"
+	"github.com/jambii1/task-3/internal/source"
+)
+
+func runServe(ctx context.Context, conRec *config.ConfigRecord, rateSource source.RateSource, args []string) error {
+	flagSet := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flagSet.String("addr", ":8080", "address to listen on")
+
+	_, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	return withMetrics(ctx, conRec, func(ctx context.Context) error {
+		return listen(ctx, *addr, server.New(rateSource, conRec.Query, server.WithBaseCurrency(conRec.BaseCurrency)).Handler())
+	})
+}
+
+// listen serves the handler until the context is done and then shuts the
+// server down gracefully.
+func listen(ctx context.Context, addr string, handler http.Handler) error {
+	const (
+		readHeaderTimeout = 5 * time.Second
+		shutdownTimeout   = 10 * time.Second
+	)
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/validate"
+)
+
+type fileReport struct {
+	File string `json:"file"`
+	*validate.Report
+}
+
+func runValidate(conRec *config.ConfigRecord, args []string) error {
+	flagSet := flag.NewFlagSet("validate", flag.ContinueOnError)
+	lenient := flagSet.Bool("lenient", false, "succeed even when records have errors, validation is strict by default")
+	encoding := flagSet.String("encoding", conRec.InputEncoding, "input encoding, detected when empty")
+
+	paths, err := parseArgs(flagSet, args)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		paths = []string{conRec.InputFile}
+	}
+
+	var (
+		reports      = make([]fileReport, 0, len(paths))
+		errorsAmount = 0
+		firstErr     error
+	)
+
+	for _, path := range paths {
+		report, err := validateFile(path, *encoding)
+		if report == nil {
+			return err
+		}
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		reports = append(reports, fileReport{File: path, Report: report})
+		errorsAmount += report.Errors
+	}
+
+	err = printJSON(reports)
+	if err != nil {
+		return err
+	}
+
+	// A file that stopped decoding is reported above up to where it broke.
+	if firstErr != nil {
+		return firstErr
+	}
+
+	if errorsAmount > 0 && !*lenient {
+		return failure.Decode(fmt.Errorf("%w: %d errors", validate.ErrInvalidInput, errorsAmount))
+	}
+
+	return nil
+}
+
+func validateFile(path, encoding string) (*validate.Report, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, failure.Input(fmt.Errorf("failed to open xml currencies file: %w", err))
+	}
+
+	_, report, err := validate.Check(file, encoding)
+	closeErr := file.Close()
+
+	switch {
+	case err != nil:
+		return report, failure.Decode(fmt.Errorf("failed to validate %s: %w", path, err))
+	case closeErr != nil:
+		return nil, failure.Input(fmt.Errorf("failed to close xml currencies file: %w", closeErr))
+	}
+
+	return report, nil
+}
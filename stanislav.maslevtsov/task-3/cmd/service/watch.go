@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/failure"
+	"github.com/jambii1/task-3/internal/source"
+)
+
+const defaultDebounce = 500 * time.Millisecond
+
+var errWatchJobs = errors.New("watch mode does not support multi-job configs")
+
+// runWatch polls the source and regenerates the output once a change has settled
+// for the debounce period. A failed run is reported and the previous output, which
+// is only ever replaced atomically, stays in place.
+func runWatch(
+	ctx context.Context,
+	conRec *config.ConfigRecord,
+	rateSource source.RateSource,
+	interval, debounce time.Duration,
+) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	modified, canTell := rateSource.(source.Modified)
+
+	var (
+		generated time.Time
+		seen      time.Time
+		changedAt time.Time
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		regenerate := true
+
+		if canTell {
+			modTime, err := modified.ModTime()
+			if err != nil {
+				logger.Error("watch: "+err.Error(), "error", err.Error())
+
+				regenerate = false
+			} else {
+				if !modTime.Equal(seen) {
+					seen, changedAt = modTime, time.Now()
+				}
+
+				regenerate = !seen.Equal(generated) && time.Since(changedAt) >= debounce
+			}
+		}
+
+		if regenerate {
+			err := generate(ctx, conRec, rateSource)
+			if err != nil {
+				logger.Error(
+					"watch: keeping previous output: "+err.Error(),
+					"error", err.Error(), "kind", failure.Kind(err), "exit_code", failure.ExitCode(err),
+				)
+			} else {
+				logger.Info("watch: regenerated "+conRec.OutputFile, "output", conRec.OutputFile)
+			}
+
+			generated = seen
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
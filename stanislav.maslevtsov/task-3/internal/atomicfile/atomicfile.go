@@ -0,0 +1,185 @@
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DefaultFileMode = os.FileMode(0o644)
+	DefaultDirMode  = os.FileMode(0o755)
+	BackupSuffix    = ".bak"
+)
+
+var ErrExists = errors.New("file already exists")
+
+type (
+	Option func(opts *options)
+
+	options struct {
+		fileMode    os.FileMode
+		backup      bool
+		noOverwrite bool
+	}
+)
+
+func WithFileMode(mode os.FileMode) Option {
+	return func(opts *options) {
+		opts.fileMode = mode
+	}
+}
+
+// WithBackup keeps the replaced file next to the new one with the ".bak" suffix.
+func WithBackup() Option {
+	return func(opts *options) {
+		opts.backup = true
+	}
+}
+
+// WithNoOverwrite makes Write fail with ErrExists instead of replacing a file.
+func WithNoOverwrite() Option {
+	return func(opts *options) {
+		opts.noOverwrite = true
+	}
+}
+
+// Write creates missing parent directories, lets write fill a temporary file in
+// the target directory, syncs it and only then moves it to path. Readers see
+// either the old or the new content, never a partial one.
+func Write(path string, write func(writer io.Writer) error, opts ...Option) error {
+	settings := options{
+		fileMode:    DefaultFileMode,
+		backup:      false,
+		noOverwrite: false,
+	}
+
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	dir := filepath.Dir(path)
+
+	err := os.MkdirAll(dir, DefaultDirMode)
+	if err != nil {
+		return fmt.Errorf("failed to make directory: %w", err)
+	}
+
+	tempPath, err := writeTemp(dir, filepath.Base(path), settings.fileMode, write)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = os.Remove(tempPath)
+	}()
+
+	if settings.noOverwrite {
+		err = os.Link(tempPath, path)
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%w: %s", ErrExists, path)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to link file: %w", err)
+		}
+
+		return syncDir(dir)
+	}
+
+	if settings.backup {
+		err = backup(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	return syncDir(dir)
+}
+
+func writeTemp(dir, base string, mode os.FileMode, write func(writer io.Writer) error) (string, error) {
+	file, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+
+	tempPath := file.Name()
+
+	err = write(file)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	closeErr := file.Close()
+
+	switch {
+	case err != nil:
+		_ = os.Remove(tempPath)
+
+		return "", fmt.Errorf("failed to write file: %w", err)
+	case closeErr != nil:
+		_ = os.Remove(tempPath)
+
+		return "", fmt.Errorf("failed to close file: %w", closeErr)
+	}
+
+	err = os.Chmod(tempPath, mode)
+	if err != nil {
+		_ = os.Remove(tempPath)
+
+		return "", fmt.Errorf("failed to set file mode: %w", err)
+	}
+
+	return tempPath, nil
+}
+
+func backup(path string) error {
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	backupPath := path + BackupSuffix
+
+	err = os.Remove(backupPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove old backup: %w", err)
+	}
+
+	err = os.Link(path, backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to back up file: %w", err)
+	}
+
+	return nil
+}
+
+func syncDir(dir string) error {
+	handle, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory: %w", err)
+	}
+
+	err = handle.Sync()
+	closeErr := handle.Close()
+
+	switch {
+	case err != nil && !errors.Is(err, os.ErrInvalid):
+		return fmt.Errorf("failed to sync directory: %w", err)
+	case closeErr != nil:
+		return fmt.Errorf("failed to close directory: %w", closeErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+package charset
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const (
+	UTF8        = "utf-8"
+	UTF16LE     = "utf-16le"
+	UTF16BE     = "utf-16be"
+	Windows1251 = "windows-1251"
+	KOI8R       = "koi8-r"
+	CP866       = "cp866"
+
+	SourceOverride    = "override"
+	SourceBOM         = "bom"
+	SourceDeclaration = "declaration"
+	SourceSniffed     = "sniffed"
+	SourceDefault     = "default"
+
+	sampleSize = 64 << 10
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported charset")
+
+	declarationPattern = regexp.MustCompile(`^<\?xml[^>]*encoding\s*=\s*["']([A-Za-z0-9._-]+)["']`)
+
+	aliases = map[string]string{
+		"utf8":         UTF8,
+		"utf-8":        UTF8,
+		"utf-16":       UTF16LE,
+		"utf-16le":     UTF16LE,
+		"utf-16be":     UTF16BE,
+		"windows-1251": Windows1251,
+		"cp1251":       Windows1251,
+		"win-1251":     Windows1251,
+		"koi8-r":       KOI8R,
+		"koi8r":        KOI8R,
+		"cp866":        CP866,
+		"ibm866":       CP866,
+		"866":          CP866,
+	}
+
+	encodings = map[string]encoding.Encoding{
+		UTF8:        unicode.UTF8,
+		UTF16LE:     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+		UTF16BE:     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+		Windows1251: charmap.Windows1251,
+		KOI8R:       charmap.KOI8R,
+		CP866:       charmap.CodePage866,
+	}
+)
+
+// Detection tells which encoding the input was read with and why.
+type Detection struct {
+	Encoding string `json:"encoding"`
+	Source   string `json:"source"`
+}
+
+func Normalize(label string) (string, error) {
+	name, ok := aliases[strings.ToLower(strings.TrimSpace(label))]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupported, label)
+	}
+
+	return name, nil
+}
+
+// NewReader returns the input converted to UTF-8. The encoding is taken from the
+// override when it is set, otherwise from a byte order mark, then from the xml
+// declaration if the bytes agree with it, and finally guessed from the content.
+func NewReader(input io.Reader, override string) (io.Reader, Detection, error) {
+	buffered := bufio.NewReaderSize(input, sampleSize)
+
+	sample, err := buffered.Peek(sampleSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, Detection{}, fmt.Errorf("failed to read input sample: %w", err)
+	}
+
+	detection, bomLen, err := detect(sample, override)
+	if err != nil {
+		return nil, Detection{}, err
+	}
+
+	_, err = buffered.Discard(bomLen)
+	if err != nil {
+		return nil, Detection{}, fmt.Errorf("failed to skip byte order mark: %w", err)
+	}
+
+	if detection.Encoding == UTF8 {
+		return buffered, detection, nil
+	}
+
+	return transform.NewReader(buffered, encodings[detection.Encoding].NewDecoder()), detection, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// NewWriter encodes the UTF-8 text written to it into the named charset. Close
+// flushes the last bytes and leaves the output open.
+func NewWriter(output io.Writer, label string) (io.WriteCloser, error) {
+	name, err := Normalize(label)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == UTF8 {
+		return nopCloser{Writer: output}, nil
+	}
+
+	return transform.NewWriter(output, encodings[name].NewEncoder()), nil
+}
+
+func detect(sample []byte, override string) (Detection, int, error) {
+	if override != "" {
+		name, err := Normalize(override)
+		if err != nil {
+			return Detection{}, 0, err
+		}
+
+		_, bomLen := detectBOM(sample)
+
+		return Detection{Encoding: name, Source: SourceOverride}, bomLen, nil
+	}
+
+	name, bomLen := detectBOM(sample)
+	if bomLen > 0 {
+		return Detection{Encoding: name, Source: SourceBOM}, bomLen, nil
+	}
+
+	name = detectUTF16(sample)
+	if name != "" {
+		return Detection{Encoding: name, Source: SourceSniffed}, 0, nil
+	}
+
+	declared := ""
+
+	match := declarationPattern.FindSubmatch(sample)
+	if match != nil {
+		declared, _ = Normalize(string(match[1]))
+	}
+
+	return detectText(sample, declared), 0, nil
+}
+
+func detectBOM(sample []byte) (string, int) {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return UTF8, 3
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return UTF16LE, 2
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return UTF16BE, 2
+	default:
+		return "", 0
+	}
+}
+
+// detectUTF16 looks at the head of the input: markup in UTF-16 without a byte order
+// mark has a zero byte in every other position, which no other supported charset has.
+func detectUTF16(sample []byte) string {
+	const headSize = 64
+
+	head := sample[:min(len(sample), headSize)]
+	if len(head) < 2 {
+		return ""
+	}
+
+	var evenZeros, oddZeros int
+
+	for index, char := range head {
+		if char != 0 {
+			continue
+		}
+
+		if index%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+
+	half := len(head) / 2
+
+	switch {
+	case oddZeros*2 > half && evenZeros == 0:
+		return UTF16LE
+	case evenZeros*2 > half && oddZeros == 0:
+		return UTF16BE
+	default:
+		return ""
+	}
+}
+
+// detectText trusts a declaration only when the bytes fit it: a document that
+// declares windows-1251 but is valid multibyte UTF-8 is read as UTF-8, and one
+// that declares UTF-8 while holding broken sequences is guessed as Cyrillic. A
+// UTF-16 declaration is never trusted here, the BOM and zero byte checks before
+// found no UTF-16 bytes.
+func detectText(sample []byte, declared string) Detection {
+	if isASCII(sample) {
+		switch declared {
+		case "":
+			return Detection{Encoding: UTF8, Source: SourceDefault}
+		case UTF16LE, UTF16BE:
+			return Detection{Encoding: UTF8, Source: SourceSniffed}
+		default:
+			return Detection{Encoding: declared, Source: SourceDeclaration}
+		}
+	}
+
+	if validUTF8(sample) {
+		source := SourceSniffed
+		if declared == UTF8 {
+			source = SourceDeclaration
+		}
+
+		return Detection{Encoding: UTF8, Source: source}
+	}
+
+	best, bestScore := Windows1251, -1.0
+
+	for _, name := range []string{Windows1251, KOI8R, CP866} {
+		score := cyrillicScore(sample, encodings[name])
+		if score > bestScore {
+			best, bestScore = name, score
+		}
+	}
+
+	if declared != "" && declared != UTF8 && declared != UTF16LE && declared != UTF16BE {
+		const tolerance = 0.8
+
+		if cyrillicScore(sample, encodings[declared]) >= bestScore*tolerance {
+			return Detection{Encoding: declared, Source: SourceDeclaration}
+		}
+	}
+
+	return Detection{Encoding: best, Source: SourceSniffed}
+}
+
+func isASCII(sample []byte) bool {
+	for _, char := range sample {
+		if char >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validUTF8 ignores a sequence cut by the end of the sample.
+func validUTF8(sample []byte) bool {
+	for index := 0; index < len(sample); {
+		char, size := utf8.DecodeRune(sample[index:])
+		if char == utf8.RuneError && size <= 1 {
+			return len(sample)-index < utf8.UTFMax && !utf8.FullRune(sample[index:])
+		}
+
+		index += size
+	}
+
+	return true
+}
+
+// cyrillicScore is the share of non-ASCII bytes that decode to the most frequent
+// lower case Russian letters, which tells apart the single-byte Cyrillic charsets.
+func cyrillicScore(sample []byte, enc encoding.Encoding) float64 {
+	const frequentLetters = "оеаинтсрвлкмдпуяыь"
+
+	decoded, err := enc.NewDecoder().Bytes(sample)
+	if err != nil {
+		return 0
+	}
+
+	var high, frequent int
+
+	for _, char := range string(decoded) {
+		if char < utf8.RuneSelf {
+			continue
+		}
+
+		high++
+
+		if strings.ContainsRune(frequentLetters, char) {
+			frequent++
+		}
+	}
+
+	if high == 0 {
+		return 0
+	}
+
+	return float64(frequent) / float64(high)
+}
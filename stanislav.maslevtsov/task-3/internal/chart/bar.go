@@ -0,0 +1,87 @@
+package chart
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-3/internal/textio"
+)
+
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// WriteBars draws a horizontal bar per value in the given order, the axis starts
+// at zero unless some value is negative.
+func WriteBars(writer io.Writer, title string, bars []Bar) error {
+	const (
+		rowHeight   = 22
+		barHeight   = 16
+		top         = 48
+		bottom      = 36
+		left        = 64
+		right       = 96
+		ticksAmount = 6
+	)
+
+	if len(bars) == 0 {
+		return ErrNoData
+	}
+
+	minValue, maxValue := 0.0, 0.0
+	for _, bar := range bars {
+		minValue, maxValue = min(minValue, bar.Value), max(maxValue, bar.Value)
+	}
+
+	ticks, decimals := niceTicks(minValue, maxValue, ticksAmount)
+	scale := linearScale{
+		domainMin: ticks[0],
+		domainMax: ticks[len(ticks)-1],
+		rangeMin:  left,
+		rangeMax:  width - right,
+	}
+
+	height := top + len(bars)*rowHeight + bottom
+	axisY := float64(top + len(bars)*rowHeight)
+
+	cnv := &canvas{Writer: textio.NewWriter(writer)}
+	cnv.open(height, title)
+
+	for _, tick := range ticks {
+		x := scale.at(tick)
+		cnv.line(x, top, x, axisY, gridColor)
+		cnv.text(x, axisY+fontSize+6, "middle", formatTick(tick, decimals))
+	}
+
+	zero := scale.at(0)
+
+	for index, bar := range bars {
+		y := float64(top + index*rowHeight + (rowHeight-barHeight)/2)
+		x, barWidth := zero, scale.at(bar.Value)-zero
+
+		if barWidth < 0 {
+			x, barWidth = zero+barWidth, -barWidth
+		}
+
+		value := textio.FormatFloat(bar.Value)
+
+		cnv.Printf(`<rect x="%s" y="%s" width="%s" height="%d" fill="%s"><title>%s</title></rect>`+"\n",
+			coord(x), coord(y), coord(barWidth), barHeight, palette[0], textio.EscapeXML(bar.Label+": "+value))
+
+		textY := y + barHeight/2 + fontSize/2 - 1
+		cnv.text(left-6, textY, "end", bar.Label)
+		cnv.text(x+barWidth+4, textY, "start", value)
+	}
+
+	cnv.line(zero, top, zero, axisY, axisColor)
+	cnv.line(left, axisY, width-right, axisY, axisColor)
+	cnv.close()
+
+	err := cnv.Err()
+	if err != nil {
+		return fmt.Errorf("failed to write bar chart: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package chart
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/jambii1/task-3/internal/textio"
+)
+
+const (
+	width    = 800
+	fontSize = 12
+
+	fontFamily = "sans-serif"
+	axisColor  = "#444444"
+	gridColor  = "#dddddd"
+)
+
+var ErrNoData = errors.New("nothing to chart")
+
+// palette is the line and bar colors, repeated when there are more series.
+var palette = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#17becf"}
+
+// canvas writes svg elements, a failed write is reported by Err.
+type canvas struct {
+	*textio.Writer
+}
+
+func (cnv *canvas) open(height int, title string) {
+	cnv.Printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`font-family="%s" font-size="%d">`+"\n", width, height, width, height, fontFamily, fontSize)
+	cnv.Printf(`<rect width="100%%" height="100%%" fill="#ffffff"/>` + "\n")
+	cnv.Printf(`<text x="%d" y="24" text-anchor="middle" font-size="16">%s</text>`+"\n", width/2, textio.EscapeXML(title))
+}
+
+func (cnv *canvas) close() {
+	cnv.Printf("</svg>\n")
+}
+
+func (cnv *canvas) line(x1, y1, x2, y2 float64, color string) {
+	cnv.Printf(`<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s"/>`+"\n",
+		coord(x1), coord(y1), coord(x2), coord(y2), color)
+}
+
+func (cnv *canvas) text(x, y float64, anchor, text string) {
+	cnv.Printf(`<text x="%s" y="%s" text-anchor="%s">%s</text>`+"\n", coord(x), coord(y), anchor, textio.EscapeXML(text))
+}
+
+// linearScale maps the domain onto the range of pixels.
+type linearScale struct {
+	domainMin, domainMax float64
+	rangeMin, rangeMax   float64
+}
+
+func (scale linearScale) at(value float64) float64 {
+	if scale.domainMax == scale.domainMin {
+		return scale.rangeMin
+	}
+
+	ratio := (value - scale.domainMin) / (scale.domainMax - scale.domainMin)
+
+	return scale.rangeMin + ratio*(scale.rangeMax-scale.rangeMin)
+}
+
+// niceTicks returns about count round tick values from at most min to at least
+// max, with the number of decimals they need.
+func niceTicks(minValue, maxValue float64, count int) ([]float64, int) {
+	const epsilon = 1e-9
+
+	if minValue == maxValue {
+		minValue, maxValue = minValue-1, maxValue+1
+	}
+
+	step := niceStep((maxValue - minValue) / float64(count))
+	decimals := max(0, -int(math.Floor(math.Log10(step))))
+
+	var ticks []float64
+
+	for index := math.Floor(minValue / step); ; index++ {
+		ticks = append(ticks, index*step)
+
+		if index*step >= maxValue-step*epsilon {
+			return ticks, decimals
+		}
+	}
+}
+
+// niceStep rounds the step up to one, two or five times a power of ten.
+func niceStep(raw float64) float64 {
+	power := math.Pow(10, math.Floor(math.Log10(raw)))
+
+	for _, factor := range []float64{1, 2, 5} {
+		if raw <= factor*power {
+			return factor * power
+		}
+	}
+
+	return 10 * power
+}
+
+func formatTick(value float64, decimals int) string {
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
+
+func coord(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
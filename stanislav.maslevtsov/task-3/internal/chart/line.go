@@ -0,0 +1,149 @@
+package chart
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jambii1/task-3/internal/textio"
+)
+
+type (
+	Point struct {
+		Date  time.Time
+		Value float64
+	}
+
+	Series struct {
+		Name   string
+		Points []Point
+	}
+)
+
+// WriteLines draws each series as a line over a shared date axis, points are
+// expected in date order. Series are told apart by color and a legend.
+func WriteLines(writer io.Writer, title, dateLayout string, series []Series) error {
+	const (
+		height       = 420
+		top          = 48
+		bottom       = 64
+		left         = 72
+		right        = 24
+		ticksAmount  = 6
+		legendStep   = 110
+		maxMarkers   = 60
+		markerRadius = 2.5
+		hoursPerDay  = 24
+	)
+
+	first, last, minValue, maxValue, ok := bounds(series)
+	if !ok {
+		return ErrNoData
+	}
+
+	if first.Equal(last) {
+		first, last = first.AddDate(0, 0, -1), last.AddDate(0, 0, 1)
+	}
+
+	ticks, decimals := niceTicks(minValue, maxValue, ticksAmount)
+	xScale := linearScale{
+		domainMin: float64(first.Unix()),
+		domainMax: float64(last.Unix()),
+		rangeMin:  left,
+		rangeMax:  width - right,
+	}
+	yScale := linearScale{
+		domainMin: ticks[0],
+		domainMax: ticks[len(ticks)-1],
+		rangeMin:  height - bottom,
+		rangeMax:  top,
+	}
+
+	cnv := &canvas{Writer: textio.NewWriter(writer)}
+	cnv.open(height, title)
+
+	for _, tick := range ticks {
+		y := yScale.at(tick)
+		cnv.line(left, y, width-right, y, gridColor)
+		cnv.text(left-6, y+fontSize/2-1, "end", formatTick(tick, decimals))
+	}
+
+	days := int(last.Sub(first).Hours() / hoursPerDay)
+	intervals := min(ticksAmount-1, days)
+
+	for index := 0; index <= intervals; index++ {
+		date := first.AddDate(0, 0, days*index/intervals)
+		x := xScale.at(float64(date.Unix()))
+		cnv.line(x, height-bottom, x, height-bottom+4, axisColor)
+		cnv.text(x, height-bottom+fontSize+6, "middle", date.Format(dateLayout))
+	}
+
+	cnv.line(left, top, left, height-bottom, axisColor)
+	cnv.line(left, height-bottom, width-right, height-bottom, axisColor)
+
+	for index, line := range series {
+		color := palette[index%len(palette)]
+		coords := make([]string, 0, len(line.Points))
+
+		for _, point := range line.Points {
+			coords = append(coords, coord(xScale.at(float64(point.Date.Unix())))+","+coord(yScale.at(point.Value)))
+		}
+
+		cnv.Printf(`<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n", strings.Join(coords, " "), color)
+
+		if len(line.Points) <= maxMarkers {
+			for _, point := range line.Points {
+				title := fmt.Sprintf("%s %s: %s", line.Name, point.Date.Format(dateLayout), textio.FormatFloat(point.Value))
+				cnv.Printf(`<circle cx="%s" cy="%s" r="%.1f" fill="%s"><title>%s</title></circle>`+"\n",
+					coord(xScale.at(float64(point.Date.Unix()))), coord(yScale.at(point.Value)), markerRadius, color,
+					textio.EscapeXML(title))
+			}
+		}
+
+		legendX := float64(left + index*legendStep)
+		cnv.Printf(`<rect x="%s" y="%d" width="12" height="12" fill="%s"/>`+"\n", coord(legendX), height-fontSize-12, color)
+		cnv.text(legendX+16, height-14, "start", line.Name)
+	}
+
+	cnv.close()
+
+	err := cnv.Err()
+	if err != nil {
+		return fmt.Errorf("failed to write line chart: %w", err)
+	}
+
+	return nil
+}
+
+// bounds returns the date and value ranges of all points, ok is false when there
+// are none.
+func bounds(series []Series) (time.Time, time.Time, float64, float64, bool) {
+	var (
+		first, last        time.Time
+		minValue, maxValue float64
+		found              bool
+	)
+
+	for _, line := range series {
+		for _, point := range line.Points {
+			if !found {
+				first, last, minValue, maxValue, found = point.Date, point.Date, point.Value, point.Value, true
+
+				continue
+			}
+
+			if point.Date.Before(first) {
+				first = point.Date
+			}
+
+			if point.Date.After(last) {
+				last = point.Date
+			}
+
+			minValue, maxValue = min(minValue, point.Value), max(maxValue, point.Value)
+		}
+	}
+
+	return first, last, minValue, maxValue, found
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+var ErrDuplicateOutput = errors.New("jobs write the same output")
+
+// JobRecord describes one conversion of a multi-job config. Fields that are left
+// out are taken from the top level of the config.
+type JobRecord struct {
+	Name          string        `yaml:"name"`
+	InputFile     string        `yaml:"input-file"`
+	InputEncoding string        `yaml:"input-encoding"`
+	OutputFile    string        `yaml:"output-file"`
+	OutputCharset string        `yaml:"output-encoding"`
+	BaseCurrency  string        `yaml:"base-currency"`
+	OutputFormat  string        `yaml:"output-format"`
+	Validation    string        `yaml:"validation"`
+	Source        *SourceRecord `yaml:"source"`
+	Query         *QueryRecord  `yaml:"query"`
+}
+
+func (conRec *ConfigRecord) Job(index int) *ConfigRecord {
+	job := conRec.Jobs[index]
+	merged := *conRec
+	merged.Jobs = nil
+
+	if job.InputFile != "" {
+		merged.InputFile = job.InputFile
+		merged.Source = SourceRecord{Type: "", Path: "", URL: "", Timeout: 0}
+	}
+
+	if job.InputEncoding != "" {
+		merged.InputEncoding = job.InputEncoding
+	}
+
+	if job.OutputFile != "" {
+		merged.OutputFile = job.OutputFile
+	}
+
+	if job.OutputCharset != "" {
+		merged.OutputCharset = job.OutputCharset
+	}
+
+	if job.BaseCurrency != "" {
+		merged.BaseCurrency = job.BaseCurrency
+	}
+
+	if job.OutputFormat != "" {
+		merged.OutputFormat = job.OutputFormat
+	}
+
+	if job.Validation != "" {
+		merged.Validation = job.Validation
+	}
+
+	if job.Source != nil {
+		merged.Source = *job.Source
+	}
+
+	if job.Query != nil {
+		merged.Query = *job.Query
+	}
+
+	return &merged
+}
+
+// validateJobs rejects jobs that resolve to one output, they run at the same time
+// and the last atomic rename would silently win.
+func (conRec *ConfigRecord) validateJobs() error {
+	outputs := make(map[string]string, len(conRec.Jobs))
+
+	for index := range conRec.Jobs {
+		output := conRec.Job(index).OutputFile
+		if output != "" {
+			output = filepath.Clean(output)
+		}
+
+		other, ok := outputs[output]
+		if ok {
+			return fmt.Errorf("%w: %s and %s write %q", ErrDuplicateOutput, other, conRec.JobName(index), output)
+		}
+
+		outputs[output] = conRec.JobName(index)
+	}
+
+	return nil
+}
+
+func (conRec *ConfigRecord) JobName(index int) string {
+	name := conRec.Jobs[index].Name
+	if name == "" {
+		name = fmt.Sprintf("job-%d", index+1)
+	}
+
+	return name
+}
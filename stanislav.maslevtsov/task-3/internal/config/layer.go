@@ -0,0 +1,343 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+const (
+	includeKey = "include"
+	jobsKey    = "jobs"
+
+	SourceDefault = "default"
+	SourceUnset   = "unset"
+)
+
+var (
+	ErrIncludeCycle      = errors.New("config include cycle")
+	ErrUndefinedVariable = errors.New("undefined config variable")
+	ErrNotMapping        = errors.New("config is not a mapping")
+
+	variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+)
+
+// Origin tells the effective value of a config key and which layer set it.
+type Origin struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// layers merges config trees, a later layer wins, and remembers for every leaf key
+// the layer that set it and for expanded scalars the variables they used.
+type layers struct {
+	root      *yaml.Node
+	sources   map[string]string
+	variables map[*yaml.Node][]string
+	lookup    func(name string) (string, bool)
+}
+
+func newLayers(lookup func(name string) (string, bool)) *layers {
+	return &layers{
+		root:      &yaml.Node{Kind: yaml.MappingNode},
+		sources:   make(map[string]string),
+		variables: make(map[*yaml.Node][]string),
+		lookup:    lookup,
+	}
+}
+
+func (lay *layers) set(key string, value *yaml.Node, source string) {
+	parent := lay.root
+	parts := strings.Split(key, ".")
+
+	for _, part := range parts[:len(parts)-1] {
+		child := mappingValue(parent, part)
+		if child == nil || child.Kind != yaml.MappingNode {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			setMappingValue(parent, part, child)
+		}
+
+		parent = child
+	}
+
+	setMappingValue(parent, parts[len(parts)-1], value)
+	lay.sources[key] = source
+}
+
+func (lay *layers) merge(dst, src *yaml.Node, prefix, source string) {
+	for index := 0; index+1 < len(src.Content); index += 2 {
+		key := src.Content[index].Value
+		value := src.Content[index+1]
+		path := prefix + key
+
+		current := mappingValue(dst, key)
+		if value.Kind == yaml.MappingNode && key != jobsKey {
+			if current == nil || current.Kind != yaml.MappingNode {
+				current = &yaml.Node{Kind: yaml.MappingNode}
+				setMappingValue(dst, key, current)
+			}
+
+			lay.merge(current, value, path+".", source)
+
+			continue
+		}
+
+		setMappingValue(dst, key, value)
+		lay.sources[path] = source
+	}
+}
+
+// loadFile merges the files the config includes, relative to its directory, and
+// then the config itself, so the including file wins over what it includes.
+func (lay *layers) loadFile(path string, visiting map[string]bool) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config path: %w", err)
+	}
+
+	if visiting[absPath] {
+		return fmt.Errorf("%w: %s", ErrIncludeCycle, path)
+	}
+
+	visiting[absPath] = true
+	defer delete(visiting, absPath)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+
+	var document yaml.Node
+
+	err = yaml.Unmarshal(data, &document)
+	if err != nil {
+		return fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%w: %s", ErrNotMapping, path)
+	}
+
+	err = lay.expand(root)
+	if err != nil {
+		return fmt.Errorf("failed to expand config file %s: %w", path, err)
+	}
+
+	includes := removeMappingValue(root, includeKey)
+
+	for _, include := range includePaths(includes) {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		err = lay.loadFile(include, visiting)
+		if err != nil {
+			return err
+		}
+	}
+
+	lay.merge(lay.root, root, "", "file "+path)
+
+	return nil
+}
+
+func includePaths(node *yaml.Node) []string {
+	switch {
+	case node == nil:
+		return nil
+	case node.Kind == yaml.ScalarNode:
+		return []string{node.Value}
+	}
+
+	paths := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		paths = append(paths, item.Value)
+	}
+
+	return paths
+}
+
+// expand replaces ${VAR} and ${VAR:-default} in every scalar of the tree. The
+// tag the parser resolved for the text with the reference is dropped, so yaml
+// resolves the substituted value again and "${WORKERS:-3}" decodes as an int.
+func (lay *layers) expand(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var err error
+
+		node.Value = variablePattern.ReplaceAllStringFunc(node.Value, func(match string) string {
+			groups := variablePattern.FindStringSubmatch(match)
+			lay.variables[node] = append(lay.variables[node], groups[1])
+
+			value, ok := lay.lookup(groups[1])
+			switch {
+			case ok:
+				return value
+			case strings.Contains(match, ":-"):
+				return groups[2]
+			default:
+				err = fmt.Errorf("%w: %s", ErrUndefinedVariable, groups[1])
+
+				return match
+			}
+		})
+
+		if len(lay.variables[node]) > 0 {
+			node.Tag = ""
+		}
+
+		return err
+	}
+
+	for _, child := range node.Content {
+		err := lay.expand(child)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeError names the key that failed to decode and the layer that set it, yaml
+// reports values from env variables and flags at line 0 and knows nothing of the
+// variables an expanded value came from.
+func (lay *layers) decodeError(err error) error {
+	for _, set := range settings {
+		node := lay.lookupKey(set.key)
+		if node == nil {
+			continue
+		}
+
+		probe := newLayers(lay.lookup)
+		probe.set(set.key, node, "")
+
+		var conRec ConfigRecord
+
+		keyErr := probe.root.Decode(&conRec)
+		if keyErr == nil {
+			continue
+		}
+
+		source := lay.sources[set.key]
+		if node.Line > 0 {
+			source += fmt.Sprintf(" line %d", node.Line)
+		}
+
+		var typeErr *yaml.TypeError
+		if errors.As(keyErr, &typeErr) && len(typeErr.Errors) > 0 {
+			keyErr = typeErr.Errors[0].Err
+		}
+
+		names := lay.variables[node]
+		if len(names) > 0 {
+			source += " via ${" + strings.Join(names, "}, ${") + "}"
+		}
+
+		return fmt.Errorf("failed to decode config key %s set by %s: %w", set.key, source, keyErr)
+	}
+
+	return fmt.Errorf("failed to decode config: %w", err)
+}
+
+func (lay *layers) origins() []Origin {
+	origins := make([]Origin, 0, len(settings)+1)
+
+	for _, set := range settings {
+		origin := Origin{Key: set.key, Value: "", Source: SourceUnset}
+
+		source, ok := lay.sources[set.key]
+		if ok {
+			origin.Source = source
+			origin.Value = nodeText(lay.lookupKey(set.key))
+		}
+
+		origins = append(origins, origin)
+	}
+
+	source, ok := lay.sources[jobsKey]
+	if ok {
+		jobs := mappingValue(lay.root, jobsKey)
+		origins = append(origins, Origin{
+			Key:    jobsKey,
+			Value:  fmt.Sprintf("%d jobs", len(jobs.Content)),
+			Source: source,
+		})
+	}
+
+	return origins
+}
+
+func (lay *layers) lookupKey(key string) *yaml.Node {
+	node := lay.root
+
+	for _, part := range strings.Split(key, ".") {
+		node = mappingValue(node, part)
+		if node == nil {
+			return nil
+		}
+	}
+
+	return node
+}
+
+func nodeText(node *yaml.Node) string {
+	switch {
+	case node == nil:
+		return ""
+	case node.Kind == yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			items = append(items, item.Value)
+		}
+
+		return strings.Join(items, ",")
+	default:
+		return node.Value
+	}
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			return mapping.Content[index+1]
+		}
+	}
+
+	return nil
+}
+
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			mapping.Content[index+1] = value
+
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+func removeMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			value := mapping.Content[index+1]
+			mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+
+			return value
+		}
+	}
+
+	return nil
+}
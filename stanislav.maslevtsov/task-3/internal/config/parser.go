@@ -1,39 +1,133 @@
 package config
 
 import (
-	"fmt"
+	"errors"
 	"os"
+	"time"
+)
+
+type (
+	SourceRecord struct {
+		Type    string        `yaml:"type"`
+		Path    string        `yaml:"path"`
+		URL     string        `yaml:"url"`
+		Timeout time.Duration `yaml:"timeout"`
+	}
+
+	QueryRecord struct {
+		Where  string   `yaml:"where"`
+		Sort   string   `yaml:"sort"`
+		Allow  []string `yaml:"allow"`
+		Deny   []string `yaml:"deny"`
+		Fields []string `yaml:"fields"`
+	}
 
-	"go.yaml.in/yaml/v4"
+	MetricsRecord struct {
+		Addr     string        `yaml:"addr"`
+		Interval time.Duration `yaml:"interval"`
+	}
+
+	ConfigRecord struct {
+		InputFile     string        `yaml:"input-file"`
+		InputEncoding string        `yaml:"input-encoding"`
+		OutputFile    string        `yaml:"output-file"`
+		OutputCharset string        `yaml:"output-encoding"`
+		BaseCurrency  string        `yaml:"base-currency"`
+		OutputFormat  string        `yaml:"output-format"`
+		OutputMode    string        `yaml:"output-permissions"`
+		OutputBackup  bool          `yaml:"output-backup"`
+		NoOverwrite   bool          `yaml:"output-no-overwrite"`
+		HistoryFile   string        `yaml:"history-file"`
+		Validation    string        `yaml:"validation"`
+		Source        SourceRecord  `yaml:"source"`
+		Query         QueryRecord   `yaml:"query"`
+		Metrics       MetricsRecord `yaml:"metrics"`
+		Workers       int           `yaml:"workers"`
+		Jobs          []JobRecord   `yaml:"jobs"`
+	}
 )
 
-type ConfigRecord struct {
-	InputFile  string `yaml:"input-file"`
-	OutputFile string `yaml:"output-file"`
+type (
+	LoadOption func(opts *loadOptions)
+
+	loadOptions struct {
+		optional  bool
+		lookup    func(name string) (string, bool)
+		overrides Overrides
+	}
+)
+
+// Optional lets the config file be missing, the config then comes from the
+// defaults, the environment and the flags alone.
+func Optional() LoadOption {
+	return func(opts *loadOptions) {
+		opts.optional = true
+	}
 }
 
-func Parse(path string) (*ConfigRecord, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
+// WithEnv sets where env variables and ${VAR} references are looked up.
+func WithEnv(lookup func(name string) (string, bool)) LoadOption {
+	return func(opts *loadOptions) {
+		opts.lookup = lookup
+	}
+}
+
+func WithOverrides(overrides Overrides) LoadOption {
+	return func(opts *loadOptions) {
+		opts.overrides = overrides
+	}
+}
+
+// Load builds the config from layers, each one overriding the previous: defaults,
+// the yaml file with its includes, CURRENCY_* env variables and flags. Origins
+// tell where every effective value came from.
+func Load(path string, opts ...LoadOption) (*ConfigRecord, []Origin, error) {
+	options := loadOptions{optional: false, lookup: os.LookupEnv, overrides: nil}
+	for _, opt := range opts {
+		opt(&options)
 	}
 
-	defer func() {
-		err := file.Close()
+	lay := newLayers(options.lookup)
+
+	for _, set := range settings {
+		if set.defaultValue != "" {
+			lay.set(set.key, set.node(set.defaultValue), SourceDefault)
+		}
+	}
+
+	_, err := os.Stat(path)
+	if err == nil || !options.optional || !errors.Is(err, os.ErrNotExist) {
+		err = lay.loadFile(path, make(map[string]bool))
 		if err != nil {
-			panic(err)
+			return nil, nil, err
 		}
-	}()
+	}
 
-	var (
-		conRec  ConfigRecord
-		decoder = yaml.NewDecoder(file)
-	)
+	for _, set := range settings {
+		value, ok := options.lookup(set.envName())
+		if ok {
+			lay.set(set.key, set.node(value), "env "+set.envName())
+		}
+	}
+
+	for _, set := range settings {
+		value, ok := options.overrides[set.key]
+		if ok {
+			lay.set(set.key, set.node(value), "flag -"+set.flagName())
+		}
+	}
+
+	var conRec ConfigRecord
+
+	err = lay.root.Decode(&conRec)
+	if err != nil {
+		return nil, nil, lay.decodeError(err)
+	}
 
-	err = decoder.Decode(&conRec)
+	err = conRec.validateJobs()
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode config file: %w", err)
+		return nil, nil, err
 	}
 
-	return &conRec, nil
+	return &conRec, lay.origins(), nil
 }
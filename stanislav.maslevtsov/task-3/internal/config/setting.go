@@ -0,0 +1,88 @@
+package config
+
+import (
+	"flag"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+const envPrefix = "CURRENCY_"
+
+type setting struct {
+	key          string
+	usage        string
+	list         bool
+	defaultValue string
+}
+
+// settings are the config keys that env variables and flags can set. Nested keys
+// are joined with a dot, "source.url" is CURRENCY_SOURCE_URL and -source-url.
+var settings = []setting{
+	{key: "input-file", usage: "input file path"},
+	{key: "input-encoding", usage: "input encoding, detected when empty"},
+	{key: "output-file", usage: "output file path"},
+	{key: "output-format", usage: "output format, taken from the output extension when empty"},
+	{key: "output-encoding", usage: "charset of the cbr output format, windows-1251 when empty"},
+	{key: "output-permissions", usage: "octal permissions of the output file"},
+	{key: "output-backup", usage: "keep the previous output as .bak"},
+	{key: "output-no-overwrite", usage: "fail when the output file exists"},
+	{key: "base-currency", usage: "currency the output rates are expressed in"},
+	{key: "history-file", usage: "history store path", defaultValue: "./history.jsonl"},
+	{key: "validation", usage: "input validation mode: strict or lenient"},
+	{key: "workers", usage: "jobs run at the same time, the number of CPUs when zero"},
+	{key: "source.type", usage: "rate source: file, directory or http", defaultValue: "file"},
+	{key: "source.path", usage: "rate source file or directory"},
+	{key: "source.url", usage: "rate source url"},
+	{key: "source.timeout", usage: "rate source request timeout"},
+	{key: "query.where", usage: "filter expression"},
+	{key: "query.sort", usage: "sort keys"},
+	{key: "query.allow", usage: "comma separated char codes to keep", list: true},
+	{key: "query.deny", usage: "comma separated char codes to drop", list: true},
+	{key: "query.fields", usage: "comma separated output fields", list: true},
+	{key: "metrics.addr", usage: "address of the metrics listener run beside watch and serve, off when empty"},
+	{key: "metrics.interval", usage: "how often the metrics listener reloads the input", defaultValue: "1m"},
+}
+
+func (set setting) envName() string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(set.key))
+}
+
+func (set setting) flagName() string {
+	return strings.ReplaceAll(set.key, ".", "-")
+}
+
+func (set setting) node(value string) *yaml.Node {
+	if !set.list {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	}
+
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		}
+	}
+
+	return node
+}
+
+// Overrides holds the settings given on the command line.
+type Overrides map[string]string
+
+// RegisterFlags adds a flag for every setting to the flag set.
+func RegisterFlags(flagSet *flag.FlagSet) Overrides {
+	overrides := make(Overrides)
+
+	for _, set := range settings {
+		flagSet.Func(set.flagName(), set.usage, func(value string) error {
+			overrides[set.key] = value
+
+			return nil
+		})
+	}
+
+	return overrides
+}
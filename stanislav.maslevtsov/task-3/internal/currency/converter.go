@@ -0,0 +1,173 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+const (
+	BaseCharCode = "RUB"
+	baseNumCode  = 643
+	baseName     = "Российский рубль"
+
+	rebaseScale = 6
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrInvalidNominal  = errors.New("invalid currency nominal")
+	ErrZeroValue       = errors.New("zero currency value")
+)
+
+// UnitRate returns the price of a single unit of the currency in rubles, rounded
+// half away from zero to as many more digits than the value as the nominal has.
+// It is exact only when the nominal is a power of ten.
+func (currency *Currency) UnitRate() (decimal.Decimal, error) {
+	if currency.Nominal == 0 {
+		return decimal.Decimal{}, fmt.Errorf("%w: %s", ErrInvalidNominal, currency.CharCode)
+	}
+
+	scale := currency.Value.Scale() + int32(len(strconv.FormatUint(uint64(currency.Nominal), 10)))
+
+	return currency.Value.Div(decimal.NewFromUint(currency.Nominal), scale)
+}
+
+// Inverse returns how many units of the currency one unit of the base is worth.
+func (currency *Currency) Inverse() (decimal.Decimal, error) {
+	if currency.inverse != nil {
+		return *currency.inverse, nil
+	}
+
+	if currency.Value.IsZero() {
+		return decimal.Decimal{}, fmt.Errorf("%w: %s", ErrZeroValue, currency.CharCode)
+	}
+
+	inverse, err := decimal.NewFromUint(currency.Nominal).Div(currency.Value, inverseScale)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("failed to invert rate of %s: %w", currency.CharCode, err)
+	}
+
+	return inverse.Normalize(), nil
+}
+
+// Rebase expresses every value in the given currency through the cross rate,
+// values stay quoted for their nominal. Values in implicit rubles get the ruble
+// as a record of its own so no rate is lost, and the base currency is marked.
+func (currencies *Currencies) Rebase(charCode string) error {
+	baseValue, baseNominal, err := currencies.quote(charCode)
+	if err != nil {
+		return fmt.Errorf("failed to get base rate: %w", err)
+	}
+
+	for _, currency := range currencies.Data {
+		err = currency.rebase(baseValue, baseNominal)
+		if err != nil {
+			return err
+		}
+	}
+
+	if currencies.QuotedIn == "" {
+		ruble := &Currency{
+			NumCode:  baseNumCode,
+			CharCode: BaseCharCode,
+			Nominal:  1,
+			Name:     baseName,
+			Value:    decimal.New(1, 0),
+		}
+
+		err = ruble.rebase(baseValue, baseNominal)
+		if err != nil {
+			return err
+		}
+
+		currencies.Data = append(currencies.Data, ruble)
+	}
+
+	for _, currency := range currencies.Data {
+		currency.Base = strings.EqualFold(currency.CharCode, charCode)
+	}
+
+	currencies.Base = strings.ToUpper(charCode)
+	currencies.QuotedIn = currencies.Base
+
+	return nil
+}
+
+// rebase quotes the value in the base currency and keeps the inverse computed
+// from the original quote, both are rounded only once.
+func (currency *Currency) rebase(baseValue, baseNominal decimal.Decimal) error {
+	value, err := currency.Value.Mul(baseNominal).Div(baseValue, rebaseScale)
+	if err != nil {
+		return fmt.Errorf("failed to rebase %s: %w", currency.CharCode, err)
+	}
+
+	currency.inverse = nil
+
+	if !currency.Value.IsZero() {
+		inverse, err := decimal.NewFromUint(currency.Nominal).Mul(baseValue).Div(currency.Value.Mul(baseNominal), inverseScale)
+		if err != nil {
+			return fmt.Errorf("failed to invert rate of %s: %w", currency.CharCode, err)
+		}
+
+		inverse = inverse.Normalize()
+		currency.inverse = &inverse
+	}
+
+	currency.Value = value.Normalize()
+
+	return nil
+}
+
+func (currencies *Currencies) Find(charCode string) (*Currency, error) {
+	for _, currency := range currencies.Data {
+		if strings.EqualFold(currency.CharCode, charCode) {
+			return currency, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, charCode)
+}
+
+// quote returns the value of the currency in the snapshot base and the nominal it
+// is quoted for. Rubles are the base unless the snapshot is quoted in another one.
+func (currencies *Currencies) quote(charCode string) (decimal.Decimal, decimal.Decimal, error) {
+	if currencies.QuotedIn == "" && strings.EqualFold(charCode, BaseCharCode) {
+		return decimal.New(1, 0), decimal.New(1, 0), nil
+	}
+
+	currency, err := currencies.Find(charCode)
+	if err != nil {
+		return decimal.Decimal{}, decimal.Decimal{}, err
+	}
+
+	if currency.Nominal == 0 {
+		return decimal.Decimal{}, decimal.Decimal{}, fmt.Errorf("%w: %s", ErrInvalidNominal, currency.CharCode)
+	}
+
+	return currency.Value, decimal.NewFromUint(currency.Nominal), nil
+}
+
+// Convert converts the amount between two currencies through their ruble cross
+// rate, the only rounding is the final one to the given scale.
+func Convert(currencies *Currencies, amount decimal.Decimal, from, to string, scale int32) (decimal.Decimal, error) {
+	fromValue, fromNominal, err := currencies.quote(from)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("failed to get source rate: %w", err)
+	}
+
+	toValue, toNominal, err := currencies.quote(to)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("failed to get target rate: %w", err)
+	}
+
+	result, err := amount.Mul(fromValue).Mul(toNominal).Div(fromNominal.Mul(toValue), scale)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("failed to convert: %w", err)
+	}
+
+	return result, nil
+}
@@ -1,42 +1,47 @@
 package currency
 
 import (
-	"encoding/xml"
 	"fmt"
-	"strconv"
-	"strings"
+	"time"
+
+	"github.com/jambii1/task-3/internal/decimal"
 )
 
-type (
-	FloatComma float32
+const DateLayout = "02.01.2006"
 
+type (
 	Currency struct {
-		NumCode  uint       `json:"num_code"  xml:"NumCode"`
-		CharCode string     `json:"char_code" xml:"CharCode"`
-		Value    FloatComma `json:"value"     xml:"Value"`
+		ID       string          `json:"-"         xml:"ID,attr"  yaml:"-"`
+		NumCode  uint            `json:"num_code"  xml:"NumCode"  yaml:"num_code"`
+		CharCode string          `json:"char_code" xml:"CharCode" yaml:"char_code"`
+		Nominal  uint            `json:"-"         xml:"Nominal"  yaml:"-"`
+		Name     string          `json:"-"         xml:"Name"     yaml:"-"`
+		Value    decimal.Decimal `json:"value"     xml:"Value"    yaml:"value"`
+		Base     bool            `json:"-"         xml:"-"        yaml:"-"`
+
+		// inverse is set by Rebase from the exact cross rate, the rebased value is
+		// rounded and would not invert back to the quote.
+		inverse *decimal.Decimal
 	}
 
+	// Currencies is one snapshot. QuotedIn is the currency the values are quoted
+	// in, rubles when empty, and Base the one they were rebased to on request.
 	Currencies struct {
-		Data []*Currency `xml:"Valute"`
+		Date     string      `xml:"Date,attr"`
+		Name     string      `xml:"name,attr"`
+		Data     []*Currency `xml:"Valute"`
+		Encoding string      `json:"-"         xml:"-" yaml:"-"`
+		QuotedIn string      `json:"-"         xml:"-" yaml:"-"`
+		Base     string      `json:"-"         xml:"-" yaml:"-"`
+		Source   string      `json:"-"         xml:"-" yaml:"-"`
 	}
 )
 
-func (floatComma *FloatComma) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
-	var rawToken string
-
-	err := decoder.DecodeElement(&rawToken, &start)
+func (currencies *Currencies) ParseDate() (time.Time, error) {
+	date, err := time.Parse(DateLayout, currencies.Date)
 	if err != nil {
-		return fmt.Errorf("failed to decode element: %w", err)
+		return time.Time{}, fmt.Errorf("failed to parse currencies date: %w", err)
 	}
 
-	rawToken = strings.Replace(rawToken, ",", ".", 1)
-
-	floatToken, err := strconv.ParseFloat(rawToken, 32)
-	if err != nil {
-		return fmt.Errorf("failed to parse raw token to float: %w", err)
-	}
-
-	*floatComma = FloatComma(floatToken)
-
-	return nil
+	return date, nil
 }
@@ -0,0 +1,153 @@
+package currency
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+var ErrIncompleteRecord = errors.New("record has no nominal or name and no reference record")
+
+type (
+	DocumentValute struct {
+		ID       string          `json:"id,omitempty"`
+		NumCode  uint            `json:"num_code"`
+		CharCode string          `json:"char_code"`
+		Nominal  uint            `json:"nominal,omitempty"`
+		Name     string          `json:"name,omitempty"`
+		Value    decimal.Decimal `json:"value"`
+	}
+
+	// Document is the json form of a whole ValCurs. Unlike the record output it
+	// keeps every attribute and element, so it converts back to the same xml.
+	Document struct {
+		Date   string           `json:"date"`
+		Name   string           `json:"name"`
+		Valute []DocumentValute `json:"valute"`
+	}
+)
+
+func NewDocument(currencies *Currencies) Document {
+	document := Document{Date: currencies.Date, Name: currencies.Name, Valute: make([]DocumentValute, 0, len(currencies.Data))}
+
+	for _, cur := range currencies.Data {
+		document.Valute = append(document.Valute, DocumentValute{
+			ID:       cur.ID,
+			NumCode:  cur.NumCode,
+			CharCode: cur.CharCode,
+			Nominal:  cur.Nominal,
+			Name:     cur.Name,
+			Value:    cur.Value,
+		})
+	}
+
+	return document
+}
+
+// Join fills what the records lack, the id, nominal and name, from the reference
+// record of the same char code, and the date and name of the document from the
+// reference itself. Values are quoted for the nominal, so it is never guessed.
+func (document *Document) Join(reference *Currencies) {
+	if document.Date == "" {
+		document.Date = reference.Date
+	}
+
+	if document.Name == "" {
+		document.Name = reference.Name
+	}
+
+	for index := range document.Valute {
+		valute := &document.Valute[index]
+
+		cur, err := reference.Find(valute.CharCode)
+		if err != nil {
+			continue
+		}
+
+		if valute.ID == "" {
+			valute.ID = cur.ID
+		}
+
+		if valute.Nominal == 0 {
+			valute.Nominal = cur.Nominal
+		}
+
+		if valute.Name == "" {
+			valute.Name = cur.Name
+		}
+	}
+}
+
+// Currencies fails on records without a nominal or a name, see Join.
+func (document Document) Currencies() (*Currencies, error) {
+	currencies := &Currencies{Date: document.Date, Name: document.Name, Data: make([]*Currency, 0, len(document.Valute))}
+
+	for _, valute := range document.Valute {
+		if valute.Nominal == 0 || valute.Name == "" {
+			return nil, fmt.Errorf("%w: %s", ErrIncompleteRecord, valute.CharCode)
+		}
+
+		currencies.Data = append(currencies.Data, &Currency{
+			ID:       valute.ID,
+			NumCode:  valute.NumCode,
+			CharCode: valute.CharCode,
+			Nominal:  valute.Nominal,
+			Name:     valute.Name,
+			Value:    valute.Value,
+		})
+	}
+
+	return currencies, nil
+}
+
+// DecodeJSON reads a Document or json records, e.g. the default num_code,
+// char_code and value output. Fields it does not know are ignored. Records that
+// lack a nominal or a name are joined with a non-nil reference snapshot.
+func DecodeJSON(input io.Reader, reference *Currencies) (*Currencies, error) {
+	reader := bufio.NewReader(input)
+
+	first, err := firstByte(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read json currencies: %w", err)
+	}
+
+	var document Document
+
+	decoder := json.NewDecoder(reader)
+
+	if first == '[' {
+		err = decoder.Decode(&document.Valute)
+	} else {
+		err = decoder.Decode(&document)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode json currencies: %w", err)
+	}
+
+	if reference != nil {
+		document.Join(reference)
+	}
+
+	return document.Currencies()
+}
+
+func firstByte(reader *bufio.Reader) (byte, error) {
+	for {
+		char, err := reader.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read byte: %w", err)
+		}
+
+		switch char {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return char, reader.UnreadByte()
+	}
+}
@@ -0,0 +1,144 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jambii1/task-3/internal/decimal"
+	"github.com/jambii1/task-3/internal/iso4217"
+)
+
+const (
+	FieldID       = "id"
+	FieldNumCode  = "num_code"
+	FieldCharCode = "char_code"
+	FieldNominal  = "nominal"
+	FieldName     = "name"
+	FieldValue    = "value"
+	FieldInverse  = "inverse"
+	FieldBase     = "base"
+
+	FieldISOName    = "iso_name"
+	FieldMinorUnits = "minor_units"
+	FieldCountries  = "countries"
+	FieldISOStatus  = "iso_status"
+
+	inverseScale = 6
+)
+
+var ErrUnknownField = errors.New("unknown currency field")
+
+func Fields() []string {
+	return []string{
+		FieldID, FieldNumCode, FieldCharCode, FieldNominal, FieldName, FieldValue, FieldInverse, FieldBase,
+		FieldISOName, FieldMinorUnits, FieldCountries, FieldISOStatus,
+	}
+}
+
+func DefaultFields() []string {
+	return []string{FieldNumCode, FieldCharCode, FieldValue}
+}
+
+func RebasedFields() []string {
+	return []string{FieldNumCode, FieldCharCode, FieldValue, FieldInverse, FieldBase}
+}
+
+// IsTextField tells the fields compared as strings from the numeric ones.
+func IsTextField(name string) bool {
+	switch name {
+	case FieldID, FieldCharCode, FieldName, FieldBase, FieldISOName, FieldCountries, FieldISOStatus:
+		return true
+	default:
+		return false
+	}
+}
+
+func ValidateField(name string) error {
+	for _, field := range Fields() {
+		if field == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownField, name)
+}
+
+// Field returns the named field as a string for text fields and as a decimal
+// for numeric ones, so callers can compare any two values of one field. The base
+// mark is a bool, minor units are nil for codes that have none.
+func (currency *Currency) Field(name string) (any, error) {
+	switch name {
+	case FieldID:
+		return currency.ID, nil
+	case FieldNumCode:
+		return decimal.NewFromUint(currency.NumCode), nil
+	case FieldCharCode:
+		return currency.CharCode, nil
+	case FieldNominal:
+		return decimal.NewFromUint(currency.Nominal), nil
+	case FieldName:
+		return currency.Name, nil
+	case FieldValue:
+		return currency.Value, nil
+	case FieldInverse:
+		return currency.Inverse()
+	case FieldBase:
+		return currency.Base, nil
+	case FieldISOName, FieldMinorUnits, FieldCountries, FieldISOStatus:
+		return currency.isoField(name), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownField, name)
+	}
+}
+
+// CompareFields orders missing values first.
+func CompareFields(left, right any) int {
+	switch {
+	case left == nil && right == nil:
+		return 0
+	case left == nil:
+		return -1
+	case right == nil:
+		return 1
+	}
+
+	switch leftValue := left.(type) {
+	case decimal.Decimal:
+		rightValue, ok := right.(decimal.Decimal)
+		if ok {
+			return leftValue.Cmp(rightValue)
+		}
+	case string:
+		rightValue, ok := right.(string)
+		if ok {
+			return strings.Compare(leftValue, rightValue)
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(left), fmt.Sprint(right))
+}
+
+// ISO joins the record onto the ISO 4217 table by both of its codes.
+func (currency *Currency) ISO() (iso4217.Entry, string) {
+	return iso4217.Lookup(currency.CharCode, currency.NumCode)
+}
+
+func (currency *Currency) isoField(name string) any {
+	entry, status := currency.ISO()
+
+	switch name {
+	case FieldISOName:
+		return entry.Name
+	case FieldCountries:
+		return strings.Join(entry.Countries, "; ")
+	case FieldISOStatus:
+		return status
+	}
+
+	if status == iso4217.StatusUnknown || entry.MinorUnits == iso4217.NoMinorUnits {
+		return nil
+	}
+
+	return decimal.New(int64(entry.MinorUnits), 0)
+}
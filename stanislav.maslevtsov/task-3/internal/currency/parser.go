@@ -2,43 +2,68 @@ package currency
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
-	"os"
 
-	"golang.org/x/text/encoding/charmap"
+	"github.com/jambii1/task-3/internal/charset"
 )
 
-func Parse(path string) (*Currencies, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open xml currencies file: %w", err)
-	}
+var ErrMultipleSnapshots = errors.New("input holds snapshots of several dates")
 
-	defer func() {
-		err := file.Close()
-		if err != nil {
-			panic(err)
-		}
-	}()
+func Decode(input io.Reader) (*Currencies, error) {
+	return DecodeCharset(input, "")
+}
 
-	var (
-		curs    Currencies
-		decoder = xml.NewDecoder(file)
-	)
+// DecodeCharset reads the input in the given encoding, an empty one is detected.
+// An archive is only read when all its ValCurs share a date, days of a longer one
+// are split by history ingest.
+func DecodeCharset(input io.Reader, encoding string) (*Currencies, error) {
+	var curs Currencies
 
-	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
-		if charset == "windows-1251" {
-			return charmap.Windows1251.NewDecoder().Reader(input), nil
+	handleHeader := func(header Header) error {
+		if curs.Date != "" && header.Date != "" && header.Date != curs.Date {
+			return fmt.Errorf("%w: %s and %s", ErrMultipleSnapshots, curs.Date, header.Date)
 		}
 
-		return input, nil
+		if curs.Date == "" {
+			curs.Date, curs.Name = header.Date, header.Name
+		}
+
+		curs.Encoding = header.Encoding
+
+		return nil
 	}
 
-	err = decoder.Decode(&curs)
+	err := Walk(input, encoding, handleHeader, func(header Header, currency *Currency) error {
+		if curs.Encoding == "" {
+			curs.Date, curs.Name, curs.Encoding = header.Date, header.Name, header.Encoding
+		}
+
+		curs.Data = append(curs.Data, currency)
+
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode xml currencies file: %w", err)
 	}
 
 	return &curs, nil
 }
+
+// NewDecoder converts the input to UTF-8 before it reaches the xml decoder, so the
+// encoding named in the prolog is not needed any more.
+func NewDecoder(input io.Reader, encoding string) (*xml.Decoder, charset.Detection, error) {
+	reader, detection, err := charset.NewReader(input, encoding)
+	if err != nil {
+		return nil, detection, fmt.Errorf("failed to detect input encoding: %w", err)
+	}
+
+	decoder := xml.NewDecoder(reader)
+
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	return decoder, detection, nil
+}
@@ -0,0 +1,103 @@
+package currency
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	valCursElement = "ValCurs"
+	valuteElement  = "Valute"
+)
+
+type Header struct {
+	Date     string
+	Name     string
+	Encoding string
+}
+
+// Stream walks the xml token by token and hands over every Valute as soon as it
+// is decoded, so memory does not grow with the input size. Files holding several
+// ValCurs elements are supported, each Valute comes with the header of its ValCurs.
+func Stream(input io.Reader, encoding string, handle func(header Header, currency *Currency) error) error {
+	return Walk(input, encoding, nil, handle)
+}
+
+// Walk is Stream that also hands over the header of every ValCurs as it starts,
+// including those without any Valute. A nil handleHeader is skipped.
+func Walk(
+	input io.Reader,
+	encoding string,
+	handleHeader func(header Header) error,
+	handle func(header Header, currency *Currency) error,
+) error {
+	decoder, detection, err := NewDecoder(input, encoding)
+	if err != nil {
+		return err
+	}
+
+	var (
+		header = Header{Encoding: detection.Encoding}
+		isRoot = true
+	)
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) && !isRoot {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read xml token: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case start.Name.Local == valuteElement:
+			var currency Currency
+
+			err = decoder.DecodeElement(&currency, &start)
+			if err != nil {
+				return fmt.Errorf("failed to decode valute: %w", err)
+			}
+
+			err = handle(header, &currency)
+			if err != nil {
+				return err
+			}
+		case start.Name.Local == valCursElement || isRoot:
+			header = readHeader(start)
+			header.Encoding = detection.Encoding
+
+			if handleHeader != nil && start.Name.Local == valCursElement {
+				err = handleHeader(header)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		isRoot = false
+	}
+}
+
+func readHeader(start xml.StartElement) Header {
+	var header Header
+
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "Date":
+			header.Date = attr.Value
+		case "name":
+			header.Name = attr.Value
+		}
+	}
+
+	return header
+}
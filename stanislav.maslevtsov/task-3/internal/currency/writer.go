@@ -1,53 +1,22 @@
 package currency
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-)
-
-func Write(path string, currencies *Currencies) error {
-	const allReadWriteMode = os.FileMode(0o666)
-
-	var file *os.File
-
-	_, err := os.Stat(path)
-
-	switch {
-	case err == nil:
-		file, err = os.Open(path)
-		if err != nil {
-			return fmt.Errorf("failed to open file: %w", err)
-		}
-	case errors.Is(err, os.ErrNotExist):
-		err := os.MkdirAll(filepath.Dir(path), allReadWriteMode)
-		if err != nil {
-			return fmt.Errorf("failed to make directory: %w", err)
-		}
+	"io"
 
-		file, err = os.Create(path)
-		if err != nil {
-			return fmt.Errorf("failed to create file: %w", err)
-		}
-	default:
-		return fmt.Errorf("failed to get file info: %w", err)
-	}
-
-	defer func() {
-		err := file.Close()
-		if err != nil {
-			panic(err)
-		}
-	}()
+	"github.com/jambii1/task-3/internal/atomicfile"
+)
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+type Encoder interface {
+	Encode(writer io.Writer, currencies *Currencies) error
+}
 
-	err = encoder.Encode(currencies.Data)
+func Write(path string, currencies *Currencies, encoder Encoder, opts ...atomicfile.Option) error {
+	err := atomicfile.Write(path, func(writer io.Writer) error {
+		return encoder.Encode(writer, currencies)
+	}, opts...)
 	if err != nil {
-		return fmt.Errorf("failed to encode to json file: %w", err)
+		return fmt.Errorf("failed to write currencies: %w", err)
 	}
 
 	return nil
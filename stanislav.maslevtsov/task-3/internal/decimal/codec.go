@@ -0,0 +1,69 @@
+package decimal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+func (decimal Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(decimal.String()), nil
+}
+
+func (decimal *Decimal) UnmarshalJSON(data []byte) error {
+	parsed, err := Parse(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+
+	*decimal = parsed
+
+	return nil
+}
+
+func (decimal Decimal) MarshalText() ([]byte, error) {
+	return []byte(decimal.String()), nil
+}
+
+func (decimal *Decimal) UnmarshalText(data []byte) error {
+	parsed, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	*decimal = parsed
+
+	return nil
+}
+
+func (decimal *Decimal) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	var rawToken string
+
+	err := decoder.DecodeElement(&rawToken, &start)
+	if err != nil {
+		return fmt.Errorf("failed to decode element: %w", err)
+	}
+
+	parsed, err := Parse(rawToken)
+	if err != nil {
+		return fmt.Errorf("failed to parse raw token to decimal: %w", err)
+	}
+
+	*decimal = parsed
+
+	return nil
+}
+
+// MarshalXML writes the value with a decimal comma, as CBR files do.
+func (decimal Decimal) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	err := encoder.EncodeElement(decimal.Format(','), start)
+	if err != nil {
+		return fmt.Errorf("failed to encode element: %w", err)
+	}
+
+	return nil
+}
+
+func (decimal Decimal) MarshalYAML() (any, error) {
+	return decimal.Float64(), nil
+}
@@ -0,0 +1,223 @@
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidDecimal  = errors.New("invalid decimal")
+	ErrDivisionByZero  = errors.New("decimal division by zero")
+	ErrNegativeScale   = errors.New("negative decimal scale")
+	errUnexpectedInput = errors.New("unexpected character")
+)
+
+const tenBase = 10
+
+// Decimal is an exact fixed-point number: unscaled * 10^-scale.
+// The zero value is 0 and every operation returns a new value.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+func New(unscaled int64, scale int32) Decimal {
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+func NewFromUint(value uint) Decimal {
+	return Decimal{unscaled: new(big.Int).SetUint64(uint64(value)), scale: 0}
+}
+
+// Parse accepts both a comma and a dot as the decimal separator. When both are
+// present the last one separates the fraction and the other groups thousands,
+// spaces are treated as grouping as well.
+func Parse(raw string) (Decimal, error) {
+	cleaned := strings.Map(func(char rune) rune {
+		if char == ' ' || char == '\u00a0' || char == '\u202f' || char == '\'' {
+			return -1
+		}
+
+		return char
+	}, strings.TrimSpace(raw))
+
+	separator := strings.LastIndexAny(cleaned, ",.")
+	integer, fraction := cleaned, ""
+
+	if separator >= 0 {
+		integer, fraction = cleaned[:separator], cleaned[separator+1:]
+		integer = strings.NewReplacer(",", "", ".", "").Replace(integer)
+	}
+
+	sign := ""
+	if strings.HasPrefix(integer, "-") || strings.HasPrefix(integer, "+") {
+		sign, integer = integer[:1], integer[1:]
+	}
+
+	if integer == "" && fraction == "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, raw)
+	}
+
+	digits := integer + fraction
+	for _, char := range digits {
+		if char < '0' || char > '9' {
+			return Decimal{}, fmt.Errorf("%w %q: %w %q", ErrInvalidDecimal, raw, errUnexpectedInput, char)
+		}
+	}
+
+	unscaled, ok := new(big.Int).SetString(sign+digits, tenBase)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, raw)
+	}
+
+	return Decimal{unscaled: unscaled, scale: int32(len(fraction))}, nil
+}
+
+func (decimal Decimal) int() *big.Int {
+	if decimal.unscaled == nil {
+		return new(big.Int)
+	}
+
+	return decimal.unscaled
+}
+
+func (decimal Decimal) Scale() int32 {
+	return decimal.scale
+}
+
+func (decimal Decimal) Sign() int {
+	return decimal.int().Sign()
+}
+
+func (decimal Decimal) IsZero() bool {
+	return decimal.Sign() == 0
+}
+
+func pow10(exponent int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(tenBase), big.NewInt(int64(exponent)), nil)
+}
+
+// rescale returns the unscaled value at a scale not less than the current one.
+func (decimal Decimal) rescale(scale int32) *big.Int {
+	if scale <= decimal.scale {
+		return new(big.Int).Set(decimal.int())
+	}
+
+	return new(big.Int).Mul(decimal.int(), pow10(scale-decimal.scale))
+}
+
+func (decimal Decimal) Cmp(other Decimal) int {
+	scale := max(decimal.scale, other.scale)
+
+	return decimal.rescale(scale).Cmp(other.rescale(scale))
+}
+
+func (decimal Decimal) Add(other Decimal) Decimal {
+	scale := max(decimal.scale, other.scale)
+
+	return Decimal{unscaled: new(big.Int).Add(decimal.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+func (decimal Decimal) Sub(other Decimal) Decimal {
+	scale := max(decimal.scale, other.scale)
+
+	return Decimal{unscaled: new(big.Int).Sub(decimal.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+func (decimal Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(decimal.int()), scale: decimal.scale}
+}
+
+func (decimal Decimal) Mul(other Decimal) Decimal {
+	return Decimal{
+		unscaled: new(big.Int).Mul(decimal.int(), other.int()),
+		scale:    decimal.scale + other.scale,
+	}
+}
+
+// Div divides with the result rounded half away from zero to the given scale.
+func (decimal Decimal) Div(other Decimal, scale int32) (Decimal, error) {
+	if other.IsZero() {
+		return Decimal{}, ErrDivisionByZero
+	}
+
+	if scale < 0 {
+		return Decimal{}, ErrNegativeScale
+	}
+
+	numerator := new(big.Int).Mul(decimal.int(), pow10(scale+other.scale+1))
+	denominator := new(big.Int).Mul(other.int(), pow10(decimal.scale))
+
+	quotient := new(big.Int).Quo(numerator, denominator)
+	remainder := new(big.Int).Rem(quotient, big.NewInt(tenBase))
+	quotient.Quo(quotient, big.NewInt(tenBase))
+
+	const half = 5
+
+	switch {
+	case remainder.Cmp(big.NewInt(half)) >= 0:
+		quotient.Add(quotient, big.NewInt(1))
+	case remainder.Cmp(big.NewInt(-half)) <= 0:
+		quotient.Sub(quotient, big.NewInt(1))
+	}
+
+	return Decimal{unscaled: quotient, scale: scale}, nil
+}
+
+// Normalize drops trailing fractional zeros.
+func (decimal Decimal) Normalize() Decimal {
+	unscaled := new(big.Int).Set(decimal.int())
+	scale := decimal.scale
+	remainder := new(big.Int)
+	ten := big.NewInt(tenBase)
+
+	for scale > 0 {
+		quotient, rem := new(big.Int).QuoRem(unscaled, ten, remainder)
+		if rem.Sign() != 0 {
+			break
+		}
+
+		unscaled = quotient
+		scale--
+	}
+
+	return Decimal{unscaled: unscaled, scale: scale}
+}
+
+func (decimal Decimal) Format(separator byte) string {
+	digits := new(big.Int).Abs(decimal.int()).String()
+	sign := ""
+
+	if decimal.Sign() < 0 {
+		sign = "-"
+	}
+
+	if decimal.scale <= 0 {
+		return sign + digits + strings.Repeat("0", int(-decimal.scale))
+	}
+
+	scale := int(decimal.scale)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	point := len(digits) - scale
+
+	return sign + digits[:point] + string(separator) + digits[point:]
+}
+
+func (decimal Decimal) String() string {
+	return decimal.Format('.')
+}
+
+func (decimal Decimal) Float64() float64 {
+	value, err := strconv.ParseFloat(decimal.String(), 64)
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
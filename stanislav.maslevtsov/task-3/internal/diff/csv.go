@@ -0,0 +1,50 @@
+package diff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+// WriteCSV writes one row per change, values that do not apply are left empty.
+func (report *Report) WriteCSV(writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+
+	err := csvWriter.Write([]string{"char_code", "status", "old_value", "new_value", "change", "change_percent"})
+	if err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	for _, change := range report.Changes {
+		err = csvWriter.Write([]string{
+			change.CharCode,
+			change.Status,
+			optional(change.OldValue),
+			optional(change.NewValue),
+			optional(change.Change),
+			optional(change.ChangePercent),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write csv record: %w", err)
+		}
+	}
+
+	csvWriter.Flush()
+
+	err = csvWriter.Error()
+	if err != nil {
+		return fmt.Errorf("failed to flush csv: %w", err)
+	}
+
+	return nil
+}
+
+func optional(value *decimal.Decimal) string {
+	if value == nil {
+		return ""
+	}
+
+	return value.String()
+}
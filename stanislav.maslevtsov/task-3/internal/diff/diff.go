@@ -0,0 +1,158 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+const (
+	StatusAdded     = "added"
+	StatusRemoved   = "removed"
+	StatusChanged   = "changed"
+	StatusUnchanged = "unchanged"
+
+	SortCode   = "code"
+	SortMovers = "movers"
+
+	resultScale = 6
+)
+
+var ErrUnknownSort = errors.New("unknown diff sort")
+
+type (
+	// Change compares unit values, so a currency whose nominal was changed between
+	// the snapshots is not reported as a jump.
+	Change struct {
+		CharCode      string           `json:"char_code"`
+		Status        string           `json:"status"`
+		OldValue      *decimal.Decimal `json:"old_value,omitempty"`
+		NewValue      *decimal.Decimal `json:"new_value,omitempty"`
+		Change        *decimal.Decimal `json:"change,omitempty"`
+		ChangePercent *decimal.Decimal `json:"change_percent,omitempty"`
+	}
+
+	Report struct {
+		OldDate string   `json:"old_date"`
+		NewDate string   `json:"new_date"`
+		Changes []Change `json:"changes"`
+	}
+)
+
+func Compare(older, newer *currency.Currencies) (*Report, error) {
+	oldValues, err := unitValues(older)
+	if err != nil {
+		return nil, err
+	}
+
+	newValues, err := unitValues(newer)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{OldDate: older.Date, NewDate: newer.Date, Changes: []Change{}}
+
+	for charCode, oldValue := range oldValues {
+		newValue, ok := newValues[charCode]
+		if !ok {
+			report.Changes = append(report.Changes, Change{
+				CharCode: charCode, Status: StatusRemoved, OldValue: &oldValue,
+				NewValue: nil, Change: nil, ChangePercent: nil,
+			})
+
+			continue
+		}
+
+		report.Changes = append(report.Changes, compareValues(charCode, oldValue, newValue))
+	}
+
+	for charCode, newValue := range newValues {
+		_, ok := oldValues[charCode]
+		if !ok {
+			report.Changes = append(report.Changes, Change{
+				CharCode: charCode, Status: StatusAdded, OldValue: nil,
+				NewValue: &newValue, Change: nil, ChangePercent: nil,
+			})
+		}
+	}
+
+	report.sortByCode()
+
+	return report, nil
+}
+
+func unitValues(currencies *currency.Currencies) (map[string]decimal.Decimal, error) {
+	values := make(map[string]decimal.Decimal, len(currencies.Data))
+
+	for _, cur := range currencies.Data {
+		value, err := cur.UnitRate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get unit rate: %w", err)
+		}
+
+		values[strings.ToUpper(cur.CharCode)] = value.Normalize()
+	}
+
+	return values, nil
+}
+
+func compareValues(charCode string, oldValue, newValue decimal.Decimal) Change {
+	const percent = 100
+
+	change := newValue.Sub(oldValue).Normalize()
+	result := Change{
+		CharCode: charCode, Status: StatusChanged, OldValue: &oldValue,
+		NewValue: &newValue, Change: &change, ChangePercent: nil,
+	}
+
+	if change.IsZero() {
+		result.Status = StatusUnchanged
+	}
+
+	changePercent, err := change.Mul(decimal.New(percent, 0)).Div(oldValue, resultScale)
+	if err == nil {
+		changePercent = changePercent.Normalize()
+		result.ChangePercent = &changePercent
+	}
+
+	return result
+}
+
+// Sort orders the changes by char code or, for movers, by the absolute percentage
+// change with the biggest first. Added and removed currencies go last.
+func (report *Report) Sort(order string) error {
+	switch order {
+	case SortCode, "":
+		report.sortByCode()
+	case SortMovers:
+		report.sortByCode()
+		slices.SortStableFunc(report.Changes, compareMovers)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownSort, order)
+	}
+
+	return nil
+}
+
+func (report *Report) sortByCode() {
+	slices.SortFunc(report.Changes, func(left, right Change) int {
+		return strings.Compare(left.CharCode, right.CharCode)
+	})
+}
+
+func compareMovers(left, right Change) int {
+	switch {
+	case left.ChangePercent == nil && right.ChangePercent == nil:
+		return 0
+	case left.ChangePercent == nil:
+		return 1
+	case right.ChangePercent == nil:
+		return -1
+	default:
+		return right.ChangePercent.Abs().Cmp(left.ChangePercent.Abs())
+	}
+}
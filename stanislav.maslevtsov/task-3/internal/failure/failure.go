@@ -0,0 +1,129 @@
+package failure
+
+import "errors"
+
+// Exit codes of the task-3 service:
+//
+//	0 - success
+//	1 - unexpected failure
+//	2 - invalid command line usage
+//	3 - invalid config
+//	4 - input could not be read
+//	5 - input could not be decoded or failed validation
+//	6 - output could not be written
+const (
+	ExitOK = iota
+	ExitFailure
+	ExitUsage
+	ExitConfig
+	ExitInput
+	ExitDecode
+	ExitOutput
+)
+
+type (
+	classError struct {
+		err      error
+		exitCode int
+		kind     string
+	}
+
+	UsageError  struct{ classError }
+	ConfigError struct{ classError }
+	InputError  struct{ classError }
+	DecodeError struct{ classError }
+	OutputError struct{ classError }
+
+	classified interface {
+		error
+		ExitCode() int
+		Kind() string
+	}
+)
+
+func (err *classError) Error() string {
+	return err.err.Error()
+}
+
+func (err *classError) Unwrap() error {
+	return err.err
+}
+
+func (err *classError) ExitCode() int {
+	return err.exitCode
+}
+
+func (err *classError) Kind() string {
+	return err.kind
+}
+
+// The constructors keep a class that is already in the chain, so an error is
+// classified where it is best understood and outer layers do not relabel it.
+
+func Usage(err error) error {
+	if isClassified(err) {
+		return err
+	}
+
+	return &UsageError{classError{err: err, exitCode: ExitUsage, kind: "usage"}}
+}
+
+func Config(err error) error {
+	if isClassified(err) {
+		return err
+	}
+
+	return &ConfigError{classError{err: err, exitCode: ExitConfig, kind: "config"}}
+}
+
+func Input(err error) error {
+	if isClassified(err) {
+		return err
+	}
+
+	return &InputError{classError{err: err, exitCode: ExitInput, kind: "input"}}
+}
+
+func Decode(err error) error {
+	if isClassified(err) {
+		return err
+	}
+
+	return &DecodeError{classError{err: err, exitCode: ExitDecode, kind: "decode"}}
+}
+
+func Output(err error) error {
+	if isClassified(err) {
+		return err
+	}
+
+	return &OutputError{classError{err: err, exitCode: ExitOutput, kind: "output"}}
+}
+
+func isClassified(err error) bool {
+	var known classified
+
+	return err == nil || errors.As(err, &known)
+}
+
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var known classified
+	if errors.As(err, &known) {
+		return known.ExitCode()
+	}
+
+	return ExitFailure
+}
+
+func Kind(err error) string {
+	var known classified
+	if errors.As(err, &known) {
+		return known.Kind()
+	}
+
+	return "failure"
+}
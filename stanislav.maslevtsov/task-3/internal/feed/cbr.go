@@ -0,0 +1,30 @@
+package feed
+
+import (
+	"io"
+
+	"github.com/jambii1/task-3/internal/currency"
+)
+
+const cbrRoot = "ValCurs"
+
+type CBR struct {
+	decode func(io.Reader) (*currency.Currencies, error)
+}
+
+// NewCBR wraps the ValCurs decoder, which may be a validating one.
+func NewCBR(decode func(io.Reader) (*currency.Currencies, error)) *CBR {
+	return &CBR{decode: decode}
+}
+
+func (*CBR) Name() string {
+	return SourceCBR
+}
+
+func (*CBR) Detect(root string) bool {
+	return root == cbrRoot
+}
+
+func (adapter *CBR) Decode(input io.Reader) (*currency.Currencies, error) {
+	return adapter.decode(input)
+}
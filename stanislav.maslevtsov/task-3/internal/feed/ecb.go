@@ -0,0 +1,130 @@
+package feed
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+	"github.com/jambii1/task-3/internal/iso4217"
+)
+
+const (
+	ecbRoot       = "Envelope"
+	ecbDateLayout = "2006-01-02"
+	ecbBase       = "EUR"
+	ecbBaseNum    = 978
+	ecbBaseName   = "Euro"
+
+	ecbScale = 6
+)
+
+var ErrNoRates = errors.New("feed has no rates")
+
+type (
+	ECB struct{}
+
+	ecbRate struct {
+		Currency string          `xml:"currency,attr"`
+		Rate     decimal.Decimal `xml:"rate,attr"`
+	}
+
+	ecbDay struct {
+		Time  string    `xml:"time,attr"`
+		Rates []ecbRate `xml:"Cube"`
+	}
+
+	ecbEnvelope struct {
+		Sender string   `xml:"Sender>name"`
+		Days   []ecbDay `xml:"Cube>Cube"`
+	}
+)
+
+func (ECB) Name() string {
+	return SourceECB
+}
+
+func (ECB) Detect(root string) bool {
+	return root == ecbRoot
+}
+
+// Decode reads the eurofxref feed, of which only the newest day is used. The feed
+// quotes currency units per euro, ValCurs quotes base units per nominal, so each
+// rate is inverted with a power of ten nominal that keeps the value from being tiny.
+func (ECB) Decode(input io.Reader) (*currency.Currencies, error) {
+	var envelope ecbEnvelope
+
+	err := xml.NewDecoder(input).Decode(&envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode eurofxref xml: %w", err)
+	}
+
+	if len(envelope.Days) == 0 {
+		return nil, ErrNoRates
+	}
+
+	day := envelope.Days[0]
+
+	date, err := time.Parse(ecbDateLayout, day.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse eurofxref date: %w", err)
+	}
+
+	currencies := &currency.Currencies{Date: date.Format(currency.DateLayout), Name: envelope.Sender}
+
+	for _, rate := range day.Rates {
+		cur, err := invertRate(rate)
+		if err != nil {
+			return nil, err
+		}
+
+		currencies.Data = append(currencies.Data, cur)
+	}
+
+	return withBase(currencies, ecbBaseNum, ecbBase, ecbBaseName), nil
+}
+
+func invertRate(rate ecbRate) (*currency.Currency, error) {
+	const (
+		nominalStep = 10
+		maxNominal  = 1_000_000
+	)
+
+	minValue := decimal.New(1, 1)
+	nominal := uint(1)
+
+	for {
+		value, err := decimal.NewFromUint(nominal).Div(rate.Rate, ecbScale)
+		if err != nil {
+			return nil, fmt.Errorf("failed to invert rate of %s: %w", rate.Currency, err)
+		}
+
+		if value.Cmp(minValue) >= 0 || nominal >= maxNominal {
+			numCode, name := lookup(rate.Currency)
+
+			return &currency.Currency{
+				NumCode:  numCode,
+				CharCode: rate.Currency,
+				Nominal:  nominal,
+				Name:     name,
+				Value:    value.Normalize(),
+			}, nil
+		}
+
+		nominal *= nominalStep
+	}
+}
+
+// lookup fills the numeric code and name that feeds other than CBR leave out.
+func lookup(charCode string) (uint, string) {
+	for _, entry := range iso4217.ByAlpha(charCode) {
+		if !entry.IsWithdrawn() {
+			return entry.NumericCode, entry.Name
+		}
+	}
+
+	return 0, ""
+}
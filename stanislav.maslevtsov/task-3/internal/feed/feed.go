@@ -0,0 +1,142 @@
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+const (
+	SourceCBR = "cbr"
+	SourceECB = "ecb"
+	SourceNBU = "nbu"
+
+	// RootJSON stands for the root of any json document, which has no element name.
+	RootJSON = "json"
+
+	sniffSize = 4 << 10
+)
+
+var (
+	ErrNoAdapter = errors.New("no feed adapter")
+	// ErrMalformed marks input that was read but did not decode, so callers can
+	// tell it from input that could not be read at all.
+	ErrMalformed = errors.New("malformed input")
+)
+
+// FeedAdapter parses one central bank feed into the ValCurs model. Detect is
+// given the local name of the xml root element, or RootJSON.
+type FeedAdapter interface {
+	Name() string
+	Detect(root string) bool
+	Decode(input io.Reader) (*currency.Currencies, error)
+}
+
+// Decoder picks the first adapter that detects the root of the input. Input with
+// a root nobody detects goes to the first adapter, whose errors explain more than
+// a bare "unknown feed" would.
+func Decoder(adapters ...FeedAdapter) func(io.Reader) (*currency.Currencies, error) {
+	return func(input io.Reader) (*currency.Currencies, error) {
+		if len(adapters) == 0 {
+			return nil, ErrNoAdapter
+		}
+
+		buffered := bufio.NewReaderSize(input, sniffSize)
+
+		sample, err := buffered.Peek(sniffSize)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("failed to read feed sample: %w", err)
+		}
+
+		adapter := adapters[0]
+		root := rootElement(sample)
+
+		for _, candidate := range adapters {
+			if candidate.Detect(root) {
+				adapter = candidate
+
+				break
+			}
+		}
+
+		currencies, err := adapter.Decode(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s feed: %w: %w", adapter.Name(), ErrMalformed, err)
+		}
+
+		currencies.Source = adapter.Name()
+
+		return currencies, nil
+	}
+}
+
+// rootElement skips the prolog, comments and doctype and returns the local name
+// of the first element. It only looks at ASCII, so an empty name is returned for
+// input it cannot read, e.g. UTF-16.
+func rootElement(sample []byte) string {
+	sample = bytes.TrimPrefix(sample, []byte{0xEF, 0xBB, 0xBF})
+
+	for {
+		sample = bytes.TrimLeft(sample, " \t\r\n")
+
+		switch {
+		case len(sample) == 0:
+			return ""
+		case sample[0] == '{' || sample[0] == '[':
+			return RootJSON
+		case sample[0] != '<':
+			return ""
+		case bytes.HasPrefix(sample, []byte("<!--")):
+			end := bytes.Index(sample, []byte("-->"))
+			if end < 0 {
+				return ""
+			}
+
+			sample = sample[end+len("-->"):]
+		case bytes.HasPrefix(sample, []byte("<?")) || bytes.HasPrefix(sample, []byte("<!")):
+			end := bytes.IndexByte(sample, '>')
+			if end < 0 {
+				return ""
+			}
+
+			sample = sample[end+1:]
+		default:
+			name := sample[1:]
+
+			end := bytes.IndexAny(name, " \t\r\n/>")
+			if end < 0 {
+				return ""
+			}
+
+			name = name[:end]
+
+			colon := bytes.IndexByte(name, ':')
+			if colon >= 0 {
+				name = name[colon+1:]
+			}
+
+			return string(name)
+		}
+	}
+}
+
+// withBase adds the currency the feed is quoted in as a record of value one, so
+// cross rates and conversions keep working. The snapshot is not marked as
+// rebased, the output keeps its default fields unless the user rebases it.
+func withBase(currencies *currency.Currencies, numCode uint, charCode, name string) *currency.Currencies {
+	currencies.Data = append(currencies.Data, &currency.Currency{
+		NumCode:  numCode,
+		CharCode: charCode,
+		Nominal:  1,
+		Name:     name,
+		Value:    decimal.New(1, 0),
+	})
+	currencies.QuotedIn = charCode
+
+	return currencies
+}
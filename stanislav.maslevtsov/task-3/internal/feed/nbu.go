@@ -0,0 +1,66 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+const (
+	nbuName     = "National Bank of Ukraine"
+	nbuBase     = "UAH"
+	nbuBaseNum  = 980
+	nbuBaseName = "Гривня"
+)
+
+type (
+	NBU struct{}
+
+	nbuRate struct {
+		NumCode  uint            `json:"r030"`
+		Name     string          `json:"txt"`
+		Rate     decimal.Decimal `json:"rate"`
+		CharCode string          `json:"cc"`
+		Date     string          `json:"exchangedate"`
+	}
+)
+
+func (NBU) Name() string {
+	return SourceNBU
+}
+
+func (NBU) Detect(root string) bool {
+	return root == RootJSON
+}
+
+// Decode reads the NBU exchange json, an array of hryvnia prices of one unit that
+// share the same exchange date.
+func (NBU) Decode(input io.Reader) (*currency.Currencies, error) {
+	var rates []nbuRate
+
+	err := json.NewDecoder(input).Decode(&rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode nbu json: %w", err)
+	}
+
+	if len(rates) == 0 {
+		return nil, ErrNoRates
+	}
+
+	currencies := &currency.Currencies{Date: rates[0].Date, Name: nbuName}
+
+	for _, rate := range rates {
+		currencies.Data = append(currencies.Data, &currency.Currency{
+			NumCode:  rate.NumCode,
+			CharCode: rate.CharCode,
+			Nominal:  1,
+			Name:     rate.Name,
+			Value:    rate.Rate,
+		})
+	}
+
+	return withBase(currencies, nbuBaseNum, nbuBase, nbuBaseName), nil
+}
@@ -0,0 +1,105 @@
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-3/internal/charset"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/textio"
+)
+
+var ErrCharsetNotSupported = errors.New("output format does not support charset selection")
+
+type (
+	// CBR writes the ValCurs layout of the CBR daily file: a Valute per line,
+	// three digit NumCode and decimal comma, in the charset given, windows-1251
+	// when empty. A CBR file comes back byte for byte, up to the record order,
+	// records without an id get no ID attribute.
+	CBR struct {
+		Charset string
+	}
+
+	// CBRJSON writes the whole ValCurs as a currency.Document, which keeps the
+	// date, the name, nominals and ids the record formats leave out.
+	CBRJSON struct{}
+)
+
+// WithCharset sets the charset of an encoder that writes a declared one.
+func WithCharset(encoder currency.Encoder, name string) (currency.Encoder, error) {
+	if name == "" {
+		return encoder, nil
+	}
+
+	cbr, ok := encoder.(CBR)
+	if !ok {
+		return nil, ErrCharsetNotSupported
+	}
+
+	normalized, err := charset.Normalize(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output charset: %w", err)
+	}
+
+	cbr.Charset = normalized
+
+	return cbr, nil
+}
+
+func (encoder CBR) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	name := encoder.Charset
+	if name == "" {
+		name = charset.Windows1251
+	}
+
+	charsetWriter, err := charset.NewWriter(writer, name)
+	if err != nil {
+		return fmt.Errorf("failed to get output charset: %w", err)
+	}
+
+	buffered := bufio.NewWriter(charsetWriter)
+
+	fmt.Fprintf(buffered, "<?xml version=\"1.0\" encoding=\"%s\"?>\n", name)
+	fmt.Fprintf(buffered, "<ValCurs Date=\"%s\" name=\"%s\">\n", textio.EscapeXML(currencies.Date), textio.EscapeXML(currencies.Name))
+
+	for _, cur := range currencies.Data {
+		fmt.Fprint(buffered, "<Valute")
+
+		if cur.ID != "" {
+			fmt.Fprintf(buffered, " ID=\"%s\"", textio.EscapeXML(cur.ID))
+		}
+
+		fmt.Fprintf(buffered,
+			"><NumCode>%03d</NumCode><CharCode>%s</CharCode><Nominal>%d</Nominal><Name>%s</Name><Value>%s</Value></Valute>\n",
+			cur.NumCode, textio.EscapeXML(cur.CharCode), cur.Nominal, textio.EscapeXML(cur.Name), cur.Value.Format(','))
+	}
+
+	fmt.Fprint(buffered, "</ValCurs>\n")
+
+	err = buffered.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write cbr xml: %w", err)
+	}
+
+	err = charsetWriter.Close()
+	if err != nil {
+		return fmt.Errorf("failed to encode cbr xml to %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (CBRJSON) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(currency.NewDocument(currencies))
+	if err != nil {
+		return fmt.Errorf("failed to encode cbr json: %w", err)
+	}
+
+	return nil
+}
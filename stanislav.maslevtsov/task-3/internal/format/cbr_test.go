@@ -0,0 +1,132 @@
+package format_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/format"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const sampleValCurs = `<?xml version="1.0" encoding="windows-1251"?>
+<ValCurs Date="02.03.2002" name="Foreign Currency Market">
+<Valute ID="R01235"><NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal><Name>Доллар США</Name><Value>30,9436</Value></Valute>
+<Valute ID="R01720"><NumCode>980</NumCode><CharCode>UAH</CharCode><Nominal>10</Nominal><Name>Украинских гривен</Name><Value>57,9100</Value></Valute>
+<Valute ID="R01335"><NumCode>398</NumCode><CharCode>KZT</CharCode><Nominal>100</Nominal><Name>Казахстанских тенге</Name><Value>20,3400</Value></Valute>
+<Valute ID="R01820"><NumCode>036</NumCode><CharCode>AUD</CharCode><Nominal>1</Nominal><Name>Австралийский доллар</Name><Value>16,0200</Value></Valute>
+</ValCurs>
+`
+
+func sampleXML(t *testing.T) []byte {
+	t.Helper()
+
+	encoded, err := charmap.Windows1251.NewEncoder().String(sampleValCurs)
+	if err != nil {
+		t.Fatalf("failed to encode sample: %v", err)
+	}
+
+	return []byte(encoded)
+}
+
+// roundTrip decodes the xml, writes it with the json encoder, reads the json back
+// and writes it as CBR xml again.
+func roundTrip(t *testing.T, original []byte, jsonEncoder currency.Encoder, join bool) (*currency.Currencies, error) {
+	t.Helper()
+
+	currencies, err := currency.Decode(bytes.NewReader(original))
+	if err != nil {
+		t.Fatalf("failed to decode xml: %v", err)
+	}
+
+	var jsonOutput bytes.Buffer
+
+	err = jsonEncoder.Encode(&jsonOutput, currencies)
+	if err != nil {
+		t.Fatalf("failed to encode json: %v", err)
+	}
+
+	var reference *currency.Currencies
+	if join {
+		reference = currencies
+	}
+
+	return currency.DecodeJSON(&jsonOutput, reference)
+}
+
+func encodeCBR(t *testing.T, currencies *currency.Currencies) []byte {
+	t.Helper()
+
+	var xmlOutput bytes.Buffer
+
+	err := format.CBR{Charset: ""}.Encode(&xmlOutput, currencies)
+	if err != nil {
+		t.Fatalf("failed to encode cbr xml: %v", err)
+	}
+
+	return xmlOutput.Bytes()
+}
+
+func TestCBRJSONRoundTrip(t *testing.T) {
+	original := sampleXML(t)
+
+	currencies, err := roundTrip(t, original, format.CBRJSON{}, false)
+	if err != nil {
+		t.Fatalf("failed to decode cbr json: %v", err)
+	}
+
+	got := encodeCBR(t, currencies)
+	if !bytes.Equal(got, original) {
+		t.Errorf("round trip changed the file:\ngot  %q\nwant %q", got, original)
+	}
+}
+
+func TestRecordsRoundTrip(t *testing.T) {
+	original := sampleXML(t)
+
+	fields := []string{
+		currency.FieldID, currency.FieldNumCode, currency.FieldCharCode,
+		currency.FieldNominal, currency.FieldName, currency.FieldValue,
+	}
+
+	encoder, err := format.WithFields(format.JSON{}, fields)
+	if err != nil {
+		t.Fatalf("failed to select fields: %v", err)
+	}
+
+	currencies, err := roundTrip(t, original, encoder, false)
+	if err != nil {
+		t.Fatalf("failed to decode json records: %v", err)
+	}
+
+	currencies.Date, currencies.Name = "02.03.2002", "Foreign Currency Market"
+
+	got := encodeCBR(t, currencies)
+	if !bytes.Equal(got, original) {
+		t.Errorf("round trip changed the file:\ngot  %q\nwant %q", got, original)
+	}
+}
+
+// TestDefaultRecordsRoundTrip edits the default num_code, char_code and value
+// output and writes it back, nominals and names come from the reference.
+func TestDefaultRecordsRoundTrip(t *testing.T) {
+	original := sampleXML(t)
+
+	currencies, err := roundTrip(t, original, format.JSON{}, true)
+	if err != nil {
+		t.Fatalf("failed to decode default json records: %v", err)
+	}
+
+	got := encodeCBR(t, currencies)
+	if !bytes.Equal(got, original) {
+		t.Errorf("round trip changed the file:\ngot  %q\nwant %q", got, original)
+	}
+}
+
+func TestDefaultRecordsNeedReference(t *testing.T) {
+	_, err := roundTrip(t, sampleXML(t), format.JSON{}, false)
+	if !errors.Is(err, currency.ErrIncompleteRecord) {
+		t.Errorf("got error %v, want %v", err, currency.ErrIncompleteRecord)
+	}
+}
@@ -0,0 +1,324 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+	"go.yaml.in/yaml/v4"
+)
+
+type (
+	JSON      struct{}
+	JSONLines struct{}
+	CSV       struct{}
+	YAML      struct{}
+	XML       struct{}
+	Markdown  struct{}
+
+	record struct {
+		currency *currency.Currency
+		fields   []string
+	}
+)
+
+func (rec record) values() ([]any, error) {
+	values := make([]any, 0, len(rec.fields))
+
+	for _, field := range rec.fields {
+		value, err := rec.currency.Field(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get field: %w", err)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (rec record) strings() ([]string, error) {
+	values, err := rec.values()
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if value == nil {
+			texts = append(texts, "")
+
+			continue
+		}
+
+		texts = append(texts, fmt.Sprint(value))
+	}
+
+	return texts, nil
+}
+
+// MarshalJSON keeps the configured field order, which a map would lose.
+func (rec record) MarshalJSON() ([]byte, error) {
+	values, err := rec.values()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+
+	buffer.WriteByte('{')
+
+	for index, field := range rec.fields {
+		if index > 0 {
+			buffer.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(field)
+
+		value, err := json.Marshal(values[index])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %s: %w", field, err)
+		}
+
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+func (rec record) yamlNode() (*yaml.Node, error) {
+	values, err := rec.values()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for index, field := range rec.fields {
+		valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(values[index])}
+
+		switch value := values[index].(type) {
+		case decimal.Decimal:
+			valueNode.Tag = "!!float"
+			if value.Scale() == 0 {
+				valueNode.Tag = "!!int"
+			}
+		case bool:
+			valueNode.Tag = "!!bool"
+		case nil:
+			valueNode.Tag, valueNode.Value = "!!null", "null"
+		}
+
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: field},
+			valueNode,
+		)
+	}
+
+	return node, nil
+}
+
+// defaultFields adds the inverse rate and the base mark to rebased output.
+func defaultFields(currencies *currency.Currencies, fields []string) []string {
+	switch {
+	case len(fields) > 0:
+		return fields
+	case currencies.Base != "":
+		return currency.RebasedFields()
+	default:
+		return currency.DefaultFields()
+	}
+}
+
+func records(currencies *currency.Currencies, fields []string) []record {
+	fields = defaultFields(currencies, fields)
+
+	recs := make([]record, 0, len(currencies.Data))
+	for _, cur := range currencies.Data {
+		recs = append(recs, record{currency: cur, fields: fields})
+	}
+
+	return recs
+}
+
+func (encoder JSON) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	return encoder.EncodeFields(writer, currencies, nil)
+}
+
+func (JSON) EncodeFields(writer io.Writer, currencies *currency.Currencies, fields []string) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(records(currencies, fields))
+	if err != nil {
+		return fmt.Errorf("failed to encode json: %w", err)
+	}
+
+	return nil
+}
+
+func (encoder JSONLines) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	return encoder.EncodeFields(writer, currencies, nil)
+}
+
+func (JSONLines) EncodeFields(writer io.Writer, currencies *currency.Currencies, fields []string) error {
+	encoder := json.NewEncoder(writer)
+
+	for _, rec := range records(currencies, fields) {
+		err := encoder.Encode(rec)
+		if err != nil {
+			return fmt.Errorf("failed to encode json line: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (encoder CSV) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	return encoder.EncodeFields(writer, currencies, nil)
+}
+
+func (CSV) EncodeFields(writer io.Writer, currencies *currency.Currencies, fields []string) error {
+	csvWriter := csv.NewWriter(writer)
+	recs := records(currencies, fields)
+
+	fields = defaultFields(currencies, fields)
+
+	err := csvWriter.Write(fields)
+	if err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	for _, rec := range recs {
+		texts, err := rec.strings()
+		if err != nil {
+			return err
+		}
+
+		err = csvWriter.Write(texts)
+		if err != nil {
+			return fmt.Errorf("failed to write csv record: %w", err)
+		}
+	}
+
+	csvWriter.Flush()
+
+	err = csvWriter.Error()
+	if err != nil {
+		return fmt.Errorf("failed to flush csv: %w", err)
+	}
+
+	return nil
+}
+
+func (encoder YAML) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	return encoder.EncodeFields(writer, currencies, nil)
+}
+
+func (YAML) EncodeFields(writer io.Writer, currencies *currency.Currencies, fields []string) error {
+	document := &yaml.Node{Kind: yaml.SequenceNode}
+
+	for _, rec := range records(currencies, fields) {
+		node, err := rec.yamlNode()
+		if err != nil {
+			return err
+		}
+
+		document.Content = append(document.Content, node)
+	}
+
+	encoder := yaml.NewEncoder(writer)
+
+	err := encoder.Encode(document)
+	if err != nil {
+		return fmt.Errorf("failed to encode yaml: %w", err)
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close yaml encoder: %w", err)
+	}
+
+	return nil
+}
+
+func (XML) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	_, err := io.WriteString(writer, xml.Header)
+	if err != nil {
+		return fmt.Errorf("failed to write xml header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+
+	err = encoder.EncodeElement(currencies, xml.StartElement{Name: xml.Name{Local: "ValCurs"}})
+	if err != nil {
+		return fmt.Errorf("failed to encode xml: %w", err)
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close xml encoder: %w", err)
+	}
+
+	_, err = io.WriteString(writer, "\n")
+	if err != nil {
+		return fmt.Errorf("failed to write xml: %w", err)
+	}
+
+	return nil
+}
+
+func (encoder Markdown) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	return encoder.EncodeFields(writer, currencies, nil)
+}
+
+func (Markdown) EncodeFields(writer io.Writer, currencies *currency.Currencies, fields []string) error {
+	recs := records(currencies, fields)
+
+	fields = defaultFields(currencies, fields)
+
+	alignments := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		if currency.IsTextField(field) {
+			alignments = append(alignments, ":---")
+		} else {
+			alignments = append(alignments, "---:")
+		}
+	}
+
+	_, err := fmt.Fprintf(writer, "| %s |\n| %s |\n", strings.Join(fields, " | "), strings.Join(alignments, " | "))
+	if err != nil {
+		return fmt.Errorf("failed to write markdown header: %w", err)
+	}
+
+	for _, rec := range recs {
+		texts, err := rec.strings()
+		if err != nil {
+			return err
+		}
+
+		for index, text := range texts {
+			texts[index] = strings.ReplaceAll(text, "|", `\|`)
+		}
+
+		_, err = fmt.Fprintf(writer, "| %s |\n", strings.Join(texts, " | "))
+		if err != nil {
+			return fmt.Errorf("failed to write markdown row: %w", err)
+		}
+	}
+
+	return nil
+}
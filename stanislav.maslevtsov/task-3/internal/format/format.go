@@ -0,0 +1,118 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jambii1/task-3/internal/currency"
+)
+
+const Default = "json"
+
+var (
+	ErrUnknownFormat      = errors.New("unknown output format")
+	ErrFieldsNotSupported = errors.New("output format does not support field selection")
+)
+
+type FieldEncoder interface {
+	EncodeFields(writer io.Writer, currencies *currency.Currencies, fields []string) error
+}
+
+type fieldsEncoder struct {
+	encoder FieldEncoder
+	fields  []string
+}
+
+func (encoder fieldsEncoder) Encode(writer io.Writer, currencies *currency.Currencies) error {
+	return encoder.encoder.EncodeFields(writer, currencies, encoder.fields)
+}
+
+// WithFields restricts the encoder output to the given fields in their order.
+func WithFields(encoder currency.Encoder, fields []string) (currency.Encoder, error) {
+	if len(fields) == 0 {
+		return encoder, nil
+	}
+
+	fieldEncoder, ok := encoder.(FieldEncoder)
+	if !ok {
+		return nil, ErrFieldsNotSupported
+	}
+
+	return fieldsEncoder{encoder: fieldEncoder, fields: fields}, nil
+}
+
+type registration struct {
+	encoder     currency.Encoder
+	contentType string
+	extensions  []string
+}
+
+var registry = map[string]registration{}
+
+func Register(name string, encoder currency.Encoder, contentType string, extensions ...string) {
+	registry[name] = registration{encoder: encoder, contentType: contentType, extensions: extensions}
+}
+
+func ContentType(name string) string {
+	reg, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return "application/octet-stream"
+	}
+
+	return reg.contentType
+}
+
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func ByName(name string) (currency.Encoder, error) {
+	reg, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+	}
+
+	return reg.encoder, nil
+}
+
+// Resolve picks the explicitly configured format first, then the one registered
+// for the output file extension, and falls back to the default json format.
+func Resolve(name, path string) (currency.Encoder, error) {
+	if name != "" {
+		return ByName(name)
+	}
+
+	extension := strings.ToLower(filepath.Ext(path))
+
+	for _, name := range Names() {
+		for _, registered := range registry[name].extensions {
+			if registered == extension {
+				return registry[name].encoder, nil
+			}
+		}
+	}
+
+	return ByName(Default)
+}
+
+func init() {
+	Register("json", JSON{}, "application/json", ".json")
+	Register("jsonl", JSONLines{}, "application/x-ndjson", ".jsonl", ".ndjson")
+	Register("csv", CSV{}, "text/csv; charset=utf-8", ".csv")
+	Register("yaml", YAML{}, "application/yaml", ".yaml", ".yml")
+	Register("xml", XML{}, "application/xml", ".xml")
+	Register("markdown", Markdown{}, "text/markdown; charset=utf-8", ".md", ".markdown")
+	Register("cbr", CBR{Charset: ""}, "application/vnd.cbr+xml; charset=windows-1251")
+	Register("cbr-json", CBRJSON{}, "application/vnd.cbr+json")
+}
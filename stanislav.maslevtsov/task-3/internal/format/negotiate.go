@@ -0,0 +1,84 @@
+package format
+
+import (
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type acceptedType struct {
+	mediaType string
+	quality   float64
+}
+
+// Negotiate picks the registered format that best matches an Accept header.
+// An empty header or a wildcard gives the default format.
+func Negotiate(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return Default, true
+	}
+
+	accepted := make([]acceptedType, 0)
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+
+		rawQuality, ok := params["q"]
+		if ok {
+			quality, err = strconv.ParseFloat(rawQuality, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			accepted = append(accepted, acceptedType{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	slices.SortStableFunc(accepted, func(left, right acceptedType) int {
+		switch {
+		case left.quality > right.quality:
+			return -1
+		case left.quality < right.quality:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for _, candidate := range accepted {
+		name, ok := matchMediaType(candidate.mediaType)
+		if ok {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+func matchMediaType(mediaType string) (string, bool) {
+	switch mediaType {
+	case "*/*", "application/*":
+		return Default, true
+	case "text/xml":
+		return "xml", true
+	case "text/*":
+		return "csv", true
+	}
+
+	for _, name := range Names() {
+		registered, _, err := mime.ParseMediaType(registry[name].contentType)
+		if err == nil && registered == mediaType {
+			return name, true
+		}
+	}
+
+	return "", false
+}
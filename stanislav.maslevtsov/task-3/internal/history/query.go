@@ -0,0 +1,137 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+const resultScale = 6
+
+type (
+	Stats struct {
+		CharCode string          `json:"char_code"`
+		From     string          `json:"from"`
+		To       string          `json:"to"`
+		Count    int             `json:"count"`
+		Min      decimal.Decimal `json:"min"`
+		Max      decimal.Decimal `json:"max"`
+		Average  decimal.Decimal `json:"average"`
+	}
+
+	Change struct {
+		Record
+
+		Change        decimal.Decimal `json:"change"`
+		ChangePercent decimal.Decimal `json:"change_percent"`
+	}
+)
+
+// UnitValue returns the rate of a single unit, so days with different nominals compare.
+func (record *Record) UnitValue() decimal.Decimal {
+	if record.Nominal == 0 {
+		return record.Value
+	}
+
+	cur := currency.Currency{Nominal: record.Nominal, Value: record.Value}
+
+	unitValue, err := cur.UnitRate()
+	if err != nil {
+		return record.Value
+	}
+
+	return unitValue
+}
+
+// Series returns records of the currency within [from, to]; zero bounds are open.
+func (store *Store) Series(charCode string, from, to time.Time) []Record {
+	points := store.series[strings.ToUpper(charCode)]
+	records := make([]Record, 0, len(points))
+
+	for _, current := range points {
+		if !from.IsZero() && current.date.Before(from) {
+			continue
+		}
+
+		if !to.IsZero() && current.date.After(to) {
+			continue
+		}
+
+		records = append(records, current.record)
+	}
+
+	return records
+}
+
+func (store *Store) Stats(charCode string, from, to time.Time) (*Stats, error) {
+	records := store.Series(charCode, from, to)
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoRecords, charCode)
+	}
+
+	first := records[0].UnitValue()
+	stats := &Stats{
+		CharCode: records[0].CharCode,
+		From:     records[0].Date,
+		To:       records[len(records)-1].Date,
+		Count:    len(records),
+		Min:      first,
+		Max:      first,
+		Average:  decimal.Decimal{},
+	}
+
+	sum := decimal.Decimal{}
+
+	for _, record := range records {
+		value := record.UnitValue()
+
+		if value.Cmp(stats.Min) < 0 {
+			stats.Min = value
+		}
+
+		if value.Cmp(stats.Max) > 0 {
+			stats.Max = value
+		}
+
+		sum = sum.Add(value)
+	}
+
+	average, err := sum.Div(decimal.New(int64(len(records)), 0), resultScale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to average history: %w", err)
+	}
+
+	stats.Min = stats.Min.Normalize()
+	stats.Max = stats.Max.Normalize()
+	stats.Average = average.Normalize()
+
+	return stats, nil
+}
+
+func (store *Store) Changes(charCode string, from, to time.Time) []Change {
+	const percent = 100
+
+	records := store.Series(charCode, from, to)
+	changes := make([]Change, 0, len(records))
+
+	for index, record := range records {
+		change := Change{Record: record, Change: decimal.Decimal{}, ChangePercent: decimal.Decimal{}}
+
+		if index > 0 {
+			previous := records[index-1].UnitValue()
+			change.Change = record.UnitValue().Sub(previous).Normalize()
+
+			changePercent, err := change.Change.Mul(decimal.New(percent, 0)).Div(previous, resultScale)
+			if err == nil {
+				change.ChangePercent = changePercent.Normalize()
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
@@ -0,0 +1,272 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+var (
+	ErrNoRecords = errors.New("no history records")
+	// ErrWrite marks failures to write the history file, as opposed to input that
+	// could not be decoded while ingesting it.
+	ErrWrite = errors.New("history write failed")
+)
+
+type (
+	Record struct {
+		Date     string          `json:"date"`
+		NumCode  uint            `json:"num_code"`
+		CharCode string          `json:"char_code"`
+		Nominal  uint            `json:"nominal"`
+		Value    decimal.Decimal `json:"value"`
+	}
+
+	point struct {
+		date   time.Time
+		record Record
+	}
+
+	// Store keeps daily rates in an append-only json lines file.
+	// When a date is ingested twice, the later records win.
+	Store struct {
+		path   string
+		series map[string][]point
+		dates  map[time.Time]struct{}
+	}
+)
+
+func Open(path string) (*Store, error) {
+	store := &Store{
+		path:   path,
+		series: make(map[string][]point),
+		dates:  make(map[time.Time]struct{}),
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	err = store.load(file)
+	closeErr := file.Close()
+
+	switch {
+	case err != nil:
+		return nil, err
+	case closeErr != nil:
+		return nil, fmt.Errorf("failed to close history file: %w", closeErr)
+	}
+
+	return store, nil
+}
+
+func (store *Store) load(input io.Reader) error {
+	scanner := bufio.NewScanner(input)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		var record Record
+
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return fmt.Errorf("failed to decode history line %d: %w", line, err)
+		}
+
+		err = store.add(record)
+		if err != nil {
+			return fmt.Errorf("failed to load history line %d: %w", line, err)
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return nil
+}
+
+func (store *Store) add(record Record) error {
+	date, err := time.Parse(currency.DateLayout, record.Date)
+	if err != nil {
+		return fmt.Errorf("failed to parse record date: %w", err)
+	}
+
+	key := strings.ToUpper(record.CharCode)
+	points := store.series[key]
+
+	index, found := slices.BinarySearchFunc(points, date, func(current point, target time.Time) int {
+		return current.date.Compare(target)
+	})
+	if found {
+		points[index].record = record
+	} else {
+		points = slices.Insert(points, index, point{date: date, record: record})
+	}
+
+	store.series[key] = points
+	store.dates[date] = struct{}{}
+
+	return nil
+}
+
+func (store *Store) Has(date time.Time) bool {
+	_, ok := store.dates[date]
+
+	return ok
+}
+
+func (store *Store) Ingest(currencies *currency.Currencies) error {
+	date, err := currencies.ParseDate()
+	if err != nil {
+		return err
+	}
+
+	return store.appendRecords(func(write func(record Record) error) error {
+		for _, cur := range currencies.Data {
+			err := write(newRecord(date, cur))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// IngestReader streams a file of one or many ValCurs snapshots into the store.
+// Unless force is set, dates stored before the call are skipped. A snapshot is
+// only written once it has been decoded completely, so a broken record leaves
+// none of its day behind. An empty encoding is detected from the input.
+func (store *Store) IngestReader(input io.Reader, encoding string, force bool) ([]string, error) {
+	var (
+		ingested []string
+		pending  []Record
+		skipped  = make(map[string]bool)
+	)
+
+	err := store.appendRecords(func(write func(record Record) error) error {
+		flush := func() error {
+			for _, record := range pending {
+				err := write(record)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(pending) > 0 {
+				ingested = append(ingested, pending[0].Date)
+			}
+
+			pending = nil
+
+			return nil
+		}
+
+		err := currency.Stream(input, encoding, func(header currency.Header, cur *currency.Currency) error {
+			date, err := time.Parse(currency.DateLayout, header.Date)
+			if err != nil {
+				return fmt.Errorf("failed to parse snapshot date: %w", err)
+			}
+
+			skip, decided := skipped[header.Date]
+			if !decided {
+				skip = store.Has(date) && !force
+				skipped[header.Date] = skip
+			}
+
+			if skip {
+				return nil
+			}
+
+			if len(pending) > 0 && pending[0].Date != header.Date {
+				err = flush()
+				if err != nil {
+					return err
+				}
+			}
+
+			pending = append(pending, newRecord(date, cur))
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return flush()
+	})
+	if err != nil {
+		return ingested, fmt.Errorf("failed to ingest snapshots: %w", err)
+	}
+
+	return ingested, nil
+}
+
+func newRecord(date time.Time, cur *currency.Currency) Record {
+	return Record{
+		Date:     date.Format(currency.DateLayout),
+		NumCode:  cur.NumCode,
+		CharCode: cur.CharCode,
+		Nominal:  cur.Nominal,
+		Value:    cur.Value,
+	}
+}
+
+func (store *Store) appendRecords(produce func(write func(record Record) error) error) error {
+	const (
+		dirMode  = os.FileMode(0o755)
+		fileMode = os.FileMode(0o644)
+	)
+
+	err := os.MkdirAll(filepath.Dir(store.path), dirMode)
+	if err != nil {
+		return fmt.Errorf("%w: failed to make history directory: %w", ErrWrite, err)
+	}
+
+	file, err := os.OpenFile(store.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, fileMode)
+	if err != nil {
+		return fmt.Errorf("%w: failed to open history file: %w", ErrWrite, err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	err = produce(func(record Record) error {
+		err := encoder.Encode(record)
+		if err != nil {
+			return fmt.Errorf("%w: failed to encode history record: %w", ErrWrite, err)
+		}
+
+		return store.add(record)
+	})
+
+	flushErr := writer.Flush()
+	closeErr := file.Close()
+
+	switch {
+	case flushErr != nil:
+		return fmt.Errorf("%w: failed to write history file: %w", ErrWrite, flushErr)
+	case closeErr != nil:
+		return fmt.Errorf("%w: failed to close history file: %w", ErrWrite, closeErr)
+	}
+
+	return err
+}
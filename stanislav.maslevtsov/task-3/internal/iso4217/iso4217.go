@@ -0,0 +1,175 @@
+package iso4217
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed codes.csv
+var codesCSV string
+
+// NoMinorUnits marks codes such as gold or SDR that have no decimal places.
+const NoMinorUnits = -1
+
+const (
+	StatusCurrent   = "current"
+	StatusWithdrawn = "withdrawn"
+	StatusMismatch  = "mismatch"
+	StatusUnknown   = "unknown"
+)
+
+type Entry struct {
+	AlphaCode   string
+	NumericCode uint
+	Name        string
+	MinorUnits  int
+	Countries   []string
+	Withdrawn   string
+}
+
+func (entry *Entry) IsWithdrawn() bool {
+	return entry.Withdrawn != ""
+}
+
+// WithdrawnBy reports whether the code was out of use on the date. The table
+// keeps withdrawal as a day, a year and month or a year, the latter two count
+// from the start of the period.
+func (entry *Entry) WithdrawnBy(date time.Time) bool {
+	if !entry.IsWithdrawn() {
+		return false
+	}
+
+	withdrawn, err := parseWithdrawn(entry.Withdrawn)
+	if err != nil {
+		return true
+	}
+
+	return !date.Before(withdrawn)
+}
+
+func parseWithdrawn(value string) (time.Time, error) {
+	var err error
+
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+		var withdrawn time.Time
+
+		withdrawn, err = time.Parse(layout, value)
+		if err == nil {
+			return withdrawn, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("failed to parse iso 4217 withdrawal date %q: %w", value, err)
+}
+
+type registry struct {
+	byAlpha map[string][]Entry
+}
+
+var loadRegistry = sync.OnceValue(func() *registry {
+	reg, err := parse(codesCSV)
+	if err != nil {
+		panic(err)
+	}
+
+	return reg
+})
+
+func parse(data string) (*registry, error) {
+	const columnsAmount = 6
+
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = columnsAmount
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read iso 4217 table: %w", err)
+	}
+
+	reg := &registry{
+		byAlpha: make(map[string][]Entry),
+	}
+
+	for _, row := range rows[1:] {
+		entry, err := parseEntry(row)
+		if err != nil {
+			return nil, err
+		}
+
+		reg.byAlpha[entry.AlphaCode] = append(reg.byAlpha[entry.AlphaCode], entry)
+	}
+
+	return reg, nil
+}
+
+func parseEntry(row []string) (Entry, error) {
+	numeric, err := strconv.ParseUint(row[1], 10, 16)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to parse iso 4217 numeric code %q: %w", row[1], err)
+	}
+
+	minorUnits := NoMinorUnits
+	if row[3] != "" {
+		minorUnits, err = strconv.Atoi(row[3])
+		if err != nil {
+			return Entry{}, fmt.Errorf("failed to parse iso 4217 minor units %q: %w", row[3], err)
+		}
+	}
+
+	if row[5] != "" {
+		_, err = parseWithdrawn(row[5])
+		if err != nil {
+			return Entry{}, err
+		}
+	}
+
+	var countries []string
+	if row[4] != "" {
+		countries = strings.Split(row[4], "|")
+	}
+
+	return Entry{
+		AlphaCode:   row[0],
+		NumericCode: uint(numeric),
+		Name:        row[2],
+		MinorUnits:  minorUnits,
+		Countries:   countries,
+		Withdrawn:   row[5],
+	}, nil
+}
+
+// ByAlpha returns current and withdrawn entries of the alphabetic code, current first.
+func ByAlpha(alphaCode string) []Entry {
+	return loadRegistry().byAlpha[strings.ToUpper(alphaCode)]
+}
+
+// Lookup joins a record onto the table by both codes. Old CBR files quote codes
+// that were withdrawn since, those are found with the withdrawn status. When the
+// codes disagree the entry of the alphabetic code is returned with the mismatch
+// status.
+func Lookup(alphaCode string, numericCode uint) (Entry, string) {
+	entries := ByAlpha(alphaCode)
+
+	for _, entry := range entries {
+		if entry.NumericCode != numericCode {
+			continue
+		}
+
+		if entry.IsWithdrawn() {
+			return entry, StatusWithdrawn
+		}
+
+		return entry, StatusCurrent
+	}
+
+	if len(entries) > 0 {
+		return entries[0], StatusMismatch
+	}
+
+	return Entry{}, StatusUnknown
+}
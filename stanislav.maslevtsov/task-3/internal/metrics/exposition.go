@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"strings"
+
+	"github.com/jambii1/task-3/internal/textio"
+)
+
+const (
+	typeGauge     = "gauge"
+	typeCounter   = "counter"
+	typeHistogram = "histogram"
+)
+
+type label struct {
+	name  string
+	value string
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// exposition writes the Prometheus text format, a failed write is reported by Err.
+type exposition struct {
+	*textio.Writer
+}
+
+func (exp *exposition) family(name, help, kind string) {
+	exp.Printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (exp *exposition) sample(name string, value float64, labels ...label) {
+	if len(labels) == 0 {
+		exp.Printf("%s %s\n", name, textio.FormatFloat(value))
+
+		return
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for _, lab := range labels {
+		pairs = append(pairs, lab.name+`="`+labelEscaper.Replace(lab.value)+`"`)
+	}
+
+	exp.Printf("%s{%s} %s\n", name, strings.Join(pairs, ","), textio.FormatFloat(value))
+}
@@ -0,0 +1,232 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/source"
+	"github.com/jambii1/task-3/internal/textio"
+	"github.com/jambii1/task-3/internal/validate"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// durationBuckets are the upper bounds in seconds of the parse duration histogram.
+var durationBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+type (
+	issueKey struct {
+		severity string
+		code     string
+	}
+
+	histogram struct {
+		counts []uint64
+		count  uint64
+		sum    float64
+	}
+
+	// Collector keeps the state of the last loads of a rate source and exposes
+	// it in the Prometheus text format.
+	Collector struct {
+		mutex        sync.Mutex
+		currencies   *currency.Currencies
+		lastSuccess  time.Time
+		lastDuration time.Duration
+		durations    histogram
+		loadErrors   map[string]uint64
+		issues       map[issueKey]uint64
+		pending      map[issueKey]uint64
+	}
+)
+
+func (hist *histogram) observe(value float64) {
+	for index, bound := range durationBuckets {
+		if value <= bound {
+			hist.counts[index]++
+		}
+	}
+
+	hist.count++
+	hist.sum += value
+}
+
+func New() *Collector {
+	return &Collector{
+		mutex:        sync.Mutex{},
+		currencies:   nil,
+		lastSuccess:  time.Time{},
+		lastDuration: 0,
+		durations:    histogram{counts: make([]uint64, len(durationBuckets)), count: 0, sum: 0},
+		loadErrors:   make(map[string]uint64),
+		issues:       make(map[issueKey]uint64),
+		pending:      make(map[issueKey]uint64),
+	}
+}
+
+// Refresh loads the latest snapshot of the source. On failure the rates of the
+// previous load stay exposed, the caller counts the error with ObserveLoadError.
+// The validation issues are those of this load only, either way.
+func (collector *Collector) Refresh(ctx context.Context, rateSource source.RateSource) error {
+	collector.mutex.Lock()
+	collector.pending = make(map[issueKey]uint64)
+	collector.mutex.Unlock()
+
+	started := time.Now()
+	currencies, err := rateSource.Fetch(ctx, time.Time{})
+	duration := time.Since(started)
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	collector.issues = collector.pending
+
+	if err != nil {
+		return fmt.Errorf("failed to fetch rates: %w", err)
+	}
+
+	collector.currencies = currencies
+	collector.lastSuccess = time.Now()
+	collector.lastDuration = duration
+	collector.durations.observe(duration.Seconds())
+
+	return nil
+}
+
+// ObserveLoadError counts a failed load by the kind of its error.
+func (collector *Collector) ObserveLoadError(kind string) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	collector.loadErrors[kind]++
+}
+
+// ObserveIssue counts a validation issue of the load in progress, it fits as the
+// report function of validate.Decoder.
+func (collector *Collector) ObserveIssue(issue validate.Issue) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	collector.pending[issueKey{severity: issue.Severity, code: issue.Code}]++
+}
+
+func (collector *Collector) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", contentType)
+
+	err := collector.Write(writer)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (collector *Collector) Write(writer io.Writer) error {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	exp := &exposition{Writer: textio.NewWriter(writer)}
+
+	collector.writeRates(exp)
+	collector.writeLoads(exp)
+
+	err := exp.Err()
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	return nil
+}
+
+func (collector *Collector) writeRates(exp *exposition) {
+	var data []*currency.Currency
+	if collector.currencies != nil {
+		data = collector.currencies.Data
+	}
+
+	exp.family("currency_rate_value", "Rate of the currency nominal in the base currency.", typeGauge)
+
+	for _, cur := range data {
+		exp.sample("currency_rate_value", cur.Value.Float64(), codeLabels(cur)...)
+	}
+
+	exp.family("currency_rate_nominal", "Units of the currency the rate is quoted for.", typeGauge)
+
+	for _, cur := range data {
+		exp.sample("currency_rate_nominal", float64(cur.Nominal), codeLabels(cur)...)
+	}
+
+	exp.family("currency_records", "Records in the last loaded snapshot.", typeGauge)
+	exp.sample("currency_records", float64(len(data)))
+}
+
+func (collector *Collector) writeLoads(exp *exposition) {
+	exp.family("currency_last_success_timestamp_seconds", "Unix time of the last successful load.", typeGauge)
+
+	lastSuccess := 0.0
+	if !collector.lastSuccess.IsZero() {
+		lastSuccess = float64(collector.lastSuccess.UnixNano()) / float64(time.Second)
+	}
+
+	exp.sample("currency_last_success_timestamp_seconds", lastSuccess)
+
+	exp.family("currency_last_parse_duration_seconds", "Time the last successful load took to read and decode the input.", typeGauge)
+	exp.sample("currency_last_parse_duration_seconds", collector.lastDuration.Seconds())
+
+	exp.family("currency_parse_duration_seconds", "Time successful loads took to read and decode the input.", typeHistogram)
+
+	for index, bound := range durationBuckets {
+		exp.sample("currency_parse_duration_seconds_bucket", float64(collector.durations.counts[index]),
+			label{name: "le", value: textio.FormatFloat(bound)})
+	}
+
+	exp.sample("currency_parse_duration_seconds_bucket", float64(collector.durations.count),
+		label{name: "le", value: "+Inf"})
+	exp.sample("currency_parse_duration_seconds_sum", collector.durations.sum)
+	exp.sample("currency_parse_duration_seconds_count", float64(collector.durations.count))
+
+	exp.family("currency_load_errors_total", "Failed loads of the input by error kind.", typeCounter)
+
+	kinds := make([]string, 0, len(collector.loadErrors))
+	for kind := range collector.loadErrors {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		exp.sample("currency_load_errors_total", float64(collector.loadErrors[kind]), label{name: "kind", value: kind})
+	}
+
+	exp.family("currency_validation_issues", "Validation issues found while decoding the input on the last load.", typeGauge)
+
+	keys := make([]issueKey, 0, len(collector.issues))
+	for key := range collector.issues {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(left, right int) bool {
+		if keys[left].severity != keys[right].severity {
+			return keys[left].severity < keys[right].severity
+		}
+
+		return keys[left].code < keys[right].code
+	})
+
+	for _, key := range keys {
+		exp.sample("currency_validation_issues", float64(collector.issues[key]),
+			label{name: "severity", value: key.severity}, label{name: "code", value: key.code})
+	}
+}
+
+func codeLabels(cur *currency.Currency) []label {
+	return []label{
+		{name: "char_code", value: cur.CharCode},
+		{name: "num_code", value: strconv.FormatUint(uint64(cur.NumCode), 10)},
+	}
+}
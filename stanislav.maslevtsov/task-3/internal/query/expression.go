@@ -0,0 +1,346 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+)
+
+var errTypeMismatch = errors.New("type mismatch")
+
+type (
+	expression interface {
+		eval(cur *currency.Currency) (bool, error)
+	}
+
+	andExpression struct{ left, right expression }
+	orExpression  struct{ left, right expression }
+	notExpression struct{ operand expression }
+
+	comparison struct {
+		field    string
+		operator string
+		values   []any
+	}
+)
+
+func (expr andExpression) eval(cur *currency.Currency) (bool, error) {
+	left, err := expr.left.eval(cur)
+	if err != nil || !left {
+		return false, err
+	}
+
+	return expr.right.eval(cur)
+}
+
+func (expr orExpression) eval(cur *currency.Currency) (bool, error) {
+	left, err := expr.left.eval(cur)
+	if err != nil || left {
+		return left, err
+	}
+
+	return expr.right.eval(cur)
+}
+
+func (expr notExpression) eval(cur *currency.Currency) (bool, error) {
+	operand, err := expr.operand.eval(cur)
+
+	return !operand, err
+}
+
+func (expr comparison) eval(cur *currency.Currency) (bool, error) {
+	value, err := cur.Field(expr.field)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate query: %w", err)
+	}
+
+	// A missing value, such as minor units of gold, matches only "!=".
+	if value == nil {
+		return expr.operator == "!=", nil
+	}
+
+	if expr.operator == "in" {
+		for _, candidate := range expr.values {
+			if expr.compare(value, candidate) == 0 {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	result := expr.compare(value, expr.values[0])
+
+	switch expr.operator {
+	case "==", "=":
+		return result == 0, nil
+	case "!=":
+		return result != 0, nil
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	default:
+		return result >= 0, nil
+	}
+}
+
+// compare ignores the case of char codes, as the allow and deny lists do.
+func (expr comparison) compare(value, literal any) int {
+	if expr.field == currency.FieldCharCode {
+		value, literal = upper(value), upper(literal)
+	}
+
+	return currency.CompareFields(value, literal)
+}
+
+func upper(value any) any {
+	text, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	return strings.ToUpper(text)
+}
+
+// parser implements the grammar:
+//
+//	expr       = and { ("or" | "||") and }
+//	and        = not { ("and" | "&&") not }
+//	not        = ("not" | "!") not | "(" expr ")" | comparison
+//	comparison = field op literal | field "in" "(" literal { "," literal } ")"
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func parseExpression(input string) (expression, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	exprParser := &parser{tokens: tokens, position: 0}
+
+	expr, err := exprParser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if exprParser.peek().kind != tokenEOF {
+		return nil, exprParser.unexpected()
+	}
+
+	return expr, nil
+}
+
+func (parser *parser) peek() token {
+	return parser.tokens[parser.position]
+}
+
+func (parser *parser) next() token {
+	current := parser.tokens[parser.position]
+	if current.kind != tokenEOF {
+		parser.position++
+	}
+
+	return current
+}
+
+func (parser *parser) unexpected() error {
+	current := parser.peek()
+	if current.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of expression", ErrSyntax)
+	}
+
+	return fmt.Errorf("%w at %d: unexpected %q", ErrSyntax, current.position, current.text)
+}
+
+func (parser *parser) isKeyword(keywords ...string) bool {
+	current := parser.peek()
+	if current.kind != tokenIdent && current.kind != tokenOperator {
+		return false
+	}
+
+	for _, keyword := range keywords {
+		if strings.EqualFold(current.text, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (parser *parser) parseOr() (expression, error) {
+	left, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.isKeyword("or", "||") {
+		parser.next()
+
+		right, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orExpression{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (parser *parser) parseAnd() (expression, error) {
+	left, err := parser.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for parser.isKeyword("and", "&&") {
+		parser.next()
+
+		right, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andExpression{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (parser *parser) parseNot() (expression, error) {
+	if parser.isKeyword("not", "!") {
+		parser.next()
+
+		operand, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpression{operand: operand}, nil
+	}
+
+	if parser.peek().kind == tokenLeftParen {
+		parser.next()
+
+		expr, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if parser.peek().kind != tokenRightParen {
+			return nil, parser.unexpected()
+		}
+
+		parser.next()
+
+		return expr, nil
+	}
+
+	return parser.parseComparison()
+}
+
+func (parser *parser) parseComparison() (expression, error) {
+	fieldToken := parser.peek()
+	if fieldToken.kind != tokenIdent {
+		return nil, parser.unexpected()
+	}
+
+	parser.next()
+
+	field := strings.ToLower(fieldToken.text)
+
+	err := currency.ValidateField(field)
+	if err != nil {
+		return nil, fmt.Errorf("%w at %d: %w", ErrSyntax, fieldToken.position, err)
+	}
+
+	if parser.isKeyword("in") {
+		parser.next()
+
+		values, err := parser.parseList(field)
+		if err != nil {
+			return nil, err
+		}
+
+		return comparison{field: field, operator: "in", values: values}, nil
+	}
+
+	operatorToken := parser.peek()
+	if operatorToken.kind != tokenOperator ||
+		!parser.isKeyword("==", "=", "!=", "<", "<=", ">", ">=") {
+		return nil, parser.unexpected()
+	}
+
+	parser.next()
+
+	value, err := parser.parseLiteral(field)
+	if err != nil {
+		return nil, err
+	}
+
+	return comparison{field: field, operator: operatorToken.text, values: []any{value}}, nil
+}
+
+func (parser *parser) parseList(field string) ([]any, error) {
+	if parser.peek().kind != tokenLeftParen {
+		return nil, parser.unexpected()
+	}
+
+	parser.next()
+
+	var values []any
+
+	for {
+		value, err := parser.parseLiteral(field)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+
+		switch parser.peek().kind {
+		case tokenComma:
+			parser.next()
+		case tokenRightParen:
+			parser.next()
+
+			return values, nil
+		default:
+			return nil, parser.unexpected()
+		}
+	}
+}
+
+func (parser *parser) parseLiteral(field string) (any, error) {
+	literal := parser.peek()
+	isText := currency.IsTextField(field)
+
+	if literal.kind != tokenString && literal.kind != tokenNumber {
+		return nil, parser.unexpected()
+	}
+
+	parser.next()
+
+	switch {
+	case literal.kind == tokenString && isText:
+		return literal.text, nil
+	case literal.kind == tokenNumber && !isText:
+		value, err := decimal.Parse(literal.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w at %d: %w", ErrSyntax, literal.position, err)
+		}
+
+		return value, nil
+	default:
+		return nil, fmt.Errorf("%w at %d: %w for field %s", ErrSyntax, literal.position, errTypeMismatch, field)
+	}
+}
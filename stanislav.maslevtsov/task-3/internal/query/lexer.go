@@ -0,0 +1,95 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrSyntax            = errors.New("query syntax error")
+	errUnterminatedQuote = errors.New("unterminated string")
+	errUnexpectedChar    = errors.New("unexpected character")
+)
+
+type tokenKind uint8
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+func tokenize(input string) ([]token, error) {
+	var (
+		tokens   []token
+		position = 0
+	)
+
+	for position < len(input) {
+		char := rune(input[position])
+
+		switch {
+		case unicode.IsSpace(char):
+			position++
+		case char == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", position: position})
+			position++
+		case char == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", position: position})
+			position++
+		case char == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", position: position})
+			position++
+		case char == '"' || char == '\'':
+			end := strings.IndexByte(input[position+1:], input[position])
+			if end < 0 {
+				return nil, fmt.Errorf("%w at %d: %w", ErrSyntax, position, errUnterminatedQuote)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: input[position+1 : position+1+end], position: position})
+			position += end + 2
+		case strings.ContainsRune("=!<>&|", char):
+			end := position + 1
+			for end < len(input) && strings.ContainsRune("=&|", rune(input[end])) {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: input[position:end], position: position})
+			position = end
+		case unicode.IsDigit(char) || char == '-' || char == '+' || char == '.':
+			end := position + 1
+			for end < len(input) && (unicode.IsDigit(rune(input[end])) || input[end] == '.') {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, text: input[position:end], position: position})
+			position = end
+		case unicode.IsLetter(char) || char == '_':
+			end := position + 1
+			for end < len(input) && (unicode.IsLetter(rune(input[end])) || unicode.IsDigit(rune(input[end])) ||
+				input[end] == '_') {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenIdent, text: input[position:end], position: position})
+			position = end
+		default:
+			return nil, fmt.Errorf("%w at %d: %w %q", ErrSyntax, position, errUnexpectedChar, char)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, text: "", position: len(input)}), nil
+}
@@ -0,0 +1,176 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+)
+
+const (
+	directionAsc  = "asc"
+	directionDesc = "desc"
+)
+
+var ErrInvalidSort = errors.New("invalid sort key")
+
+type (
+	sortKey struct {
+		field      string
+		descending bool
+	}
+
+	Query struct {
+		where  expression
+		allow  map[string]struct{}
+		deny   map[string]struct{}
+		sort   []sortKey
+		Fields []string
+	}
+)
+
+func New(record config.QueryRecord) (*Query, error) {
+	query := &Query{
+		where:  nil,
+		allow:  codeSet(record.Allow),
+		deny:   codeSet(record.Deny),
+		sort:   nil,
+		Fields: nil,
+	}
+
+	if strings.TrimSpace(record.Where) != "" {
+		where, err := parseExpression(record.Where)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse where: %w", err)
+		}
+
+		query.where = where
+	}
+
+	sort, err := parseSort(record.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	query.sort = sort
+
+	for _, field := range record.Fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+
+		err := currency.ValidateField(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse fields: %w", err)
+		}
+
+		query.Fields = append(query.Fields, field)
+	}
+
+	return query, nil
+}
+
+func codeSet(codes []string) map[string]struct{} {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	set := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(code)] = struct{}{}
+	}
+
+	return set
+}
+
+// parseSort reads keys like "char_code asc, value desc", ascending by default.
+func parseSort(rawSort string) ([]sortKey, error) {
+	var keys []sortKey
+
+	for _, rawKey := range strings.Split(rawSort, ",") {
+		parts := strings.Fields(strings.ToLower(rawKey))
+
+		switch {
+		case len(parts) == 0:
+			continue
+		case len(parts) > 2 || (len(parts) == 2 && parts[1] != directionAsc && parts[1] != directionDesc):
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSort, strings.TrimSpace(rawKey))
+		}
+
+		err := currency.ValidateField(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSort, err)
+		}
+
+		keys = append(keys, sortKey{field: parts[0], descending: len(parts) == 2 && parts[1] == directionDesc})
+	}
+
+	return keys, nil
+}
+
+func (query *Query) Match(cur *currency.Currency) (bool, error) {
+	code := strings.ToUpper(cur.CharCode)
+
+	if query.allow != nil {
+		_, ok := query.allow[code]
+		if !ok {
+			return false, nil
+		}
+	}
+
+	_, denied := query.deny[code]
+	if denied {
+		return false, nil
+	}
+
+	if query.where == nil {
+		return true, nil
+	}
+
+	return query.where.eval(cur)
+}
+
+func (query *Query) Compare(left, right *currency.Currency) int {
+	if len(query.sort) == 0 {
+		return currency.Compare(left, right)
+	}
+
+	for _, key := range query.sort {
+		leftValue, _ := left.Field(key.field)
+		rightValue, _ := right.Field(key.field)
+
+		result := currency.CompareFields(leftValue, rightValue)
+		if key.descending {
+			result = -result
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+// Apply filters the currencies in place and sorts them, by value descending
+// unless sort keys are configured.
+func (query *Query) Apply(currencies *currency.Currencies) error {
+	filtered := currencies.Data[:0]
+
+	for _, cur := range currencies.Data {
+		ok, err := query.Match(cur)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			filtered = append(filtered, cur)
+		}
+	}
+
+	currencies.Data = filtered
+	slices.SortStableFunc(currencies.Data, query.Compare)
+
+	return nil
+}
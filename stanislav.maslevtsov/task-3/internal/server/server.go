@@ -0,0 +1,315 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/format"
+	"github.com/jambii1/task-3/internal/query"
+	"github.com/jambii1/task-3/internal/source"
+)
+
+const (
+	etagLen          = 32
+	defaultCacheSize = 32
+)
+
+var errNotAcceptable = errors.New("no acceptable format")
+
+type (
+	snapshot struct {
+		currencies *currency.Currencies
+		modTime    time.Time
+		lastUse    uint64
+	}
+
+	// view is a snapshot prepared for one request and the fields to encode.
+	view struct {
+		currencies *currency.Currencies
+		fields     []string
+		modTime    time.Time
+	}
+
+	Option func(server *Server)
+
+	// Server keeps the snapshots of the dates it was asked for, at most cacheSize
+	// of them, dropping the least recently used one first.
+	Server struct {
+		rateSource   source.RateSource
+		queryRecord  config.QueryRecord
+		baseCurrency string
+		cacheSize    int
+		mutex        sync.Mutex
+		cache        map[string]snapshot
+		uses         uint64
+	}
+)
+
+// WithBaseCurrency rebases the rates unless a request asks for another base.
+func WithBaseCurrency(charCode string) Option {
+	return func(server *Server) {
+		server.baseCurrency = charCode
+	}
+}
+
+func WithCacheSize(size int) Option {
+	return func(server *Server) {
+		server.cacheSize = max(1, size)
+	}
+}
+
+func New(rateSource source.RateSource, queryRecord config.QueryRecord, opts ...Option) *Server {
+	server := &Server{
+		rateSource:   rateSource,
+		queryRecord:  queryRecord,
+		baseCurrency: "",
+		cacheSize:    defaultCacheSize,
+		mutex:        sync.Mutex{},
+		cache:        make(map[string]snapshot),
+		uses:         0,
+	}
+
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	return server
+}
+
+func (server *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rates", server.handleRates)
+	mux.HandleFunc("GET /rates/{charCode}", server.handleRate)
+
+	return mux
+}
+
+// load fetches the snapshot again only when the source reports a newer
+// modification time, sources that cannot tell are fetched on every request.
+func (server *Server) load(ctx context.Context, date time.Time) (*currency.Currencies, time.Time, error) {
+	modified, canTell := server.rateSource.(source.Modified)
+	if !canTell {
+		currencies, err := server.rateSource.Fetch(ctx, date)
+
+		return currencies, time.Time{}, err
+	}
+
+	modTime, err := modified.ModTime()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to check source: %w", err)
+	}
+
+	key := date.Format(currency.DateLayout)
+
+	cached, ok := server.cached(key)
+	if ok && cached.modTime.Equal(modTime) {
+		return cached.currencies, modTime, nil
+	}
+
+	currencies, err := server.rateSource.Fetch(ctx, date)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	server.store(key, snapshot{currencies: currencies, modTime: modTime, lastUse: 0})
+
+	return currencies, modTime, nil
+}
+
+func (server *Server) cached(key string) (snapshot, bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	cached, ok := server.cache[key]
+	if ok {
+		server.uses++
+		cached.lastUse = server.uses
+		server.cache[key] = cached
+	}
+
+	return cached, ok
+}
+
+func (server *Server) store(key string, snap snapshot) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	_, replaced := server.cache[key]
+	if !replaced && len(server.cache) >= server.cacheSize {
+		oldestKey, oldestUse := "", uint64(0)
+
+		for cachedKey, cached := range server.cache {
+			if oldestKey == "" || cached.lastUse < oldestUse {
+				oldestKey, oldestUse = cachedKey, cached.lastUse
+			}
+		}
+
+		delete(server.cache, oldestKey)
+	}
+
+	server.uses++
+	snap.lastUse = server.uses
+	server.cache[key] = snap
+}
+
+// prepare applies the query and base currency of the request, fields, sort and
+// base-currency parameters override the configured ones.
+func (server *Server) prepare(request *http.Request) (view, int, error) {
+	queryValues := request.URL.Query()
+
+	var date time.Time
+
+	rawDate := queryValues.Get("date")
+	if rawDate != "" {
+		parsed, err := time.Parse(currency.DateLayout, rawDate)
+		if err != nil {
+			return view{}, http.StatusBadRequest, fmt.Errorf("failed to parse date: %w", err)
+		}
+
+		date = parsed
+	}
+
+	loaded, modTime, err := server.load(request.Context(), date)
+
+	switch {
+	case errors.Is(err, source.ErrNoSnapshot):
+		return view{}, http.StatusNotFound, err
+	case err != nil:
+		return view{}, http.StatusInternalServerError, err
+	}
+
+	record := server.queryRecord
+	if queryValues.Has("sort") {
+		record.Sort = queryValues.Get("sort")
+	}
+
+	if queryValues.Has("fields") {
+		record.Fields = strings.Split(queryValues.Get("fields"), ",")
+	}
+
+	baseCurrency := server.baseCurrency
+	if queryValues.Has("base-currency") {
+		baseCurrency = queryValues.Get("base-currency")
+	}
+
+	currencyQuery, err := query.New(record)
+	if err != nil {
+		return view{}, http.StatusBadRequest, err
+	}
+
+	currencies := clone(loaded)
+
+	if baseCurrency != "" {
+		err = currencies.Rebase(baseCurrency)
+		if err != nil {
+			return view{}, http.StatusBadRequest, err
+		}
+	}
+
+	err = currencyQuery.Apply(currencies)
+	if err != nil {
+		return view{}, http.StatusBadRequest, err
+	}
+
+	return view{currencies: currencies, fields: currencyQuery.Fields, modTime: modTime}, http.StatusOK, nil
+}
+
+// clone copies the records as well, the cached snapshot is shared between
+// requests and Rebase rewrites the records it is given.
+func clone(loaded *currency.Currencies) *currency.Currencies {
+	currencies := *loaded
+	currencies.Data = make([]*currency.Currency, 0, len(loaded.Data))
+
+	for _, cur := range loaded.Data {
+		copied := *cur
+		currencies.Data = append(currencies.Data, &copied)
+	}
+
+	return &currencies
+}
+
+func (server *Server) handleRates(writer http.ResponseWriter, request *http.Request) {
+	prepared, status, err := server.prepare(request)
+	if err != nil {
+		http.Error(writer, err.Error(), status)
+
+		return
+	}
+
+	server.respond(writer, request, prepared)
+}
+
+func (server *Server) handleRate(writer http.ResponseWriter, request *http.Request) {
+	prepared, status, err := server.prepare(request)
+	if err != nil {
+		http.Error(writer, err.Error(), status)
+
+		return
+	}
+
+	found, err := prepared.currencies.Find(request.PathValue("charCode"))
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	prepared.currencies.Data = []*currency.Currency{found}
+
+	server.respond(writer, request, prepared)
+}
+
+func (server *Server) respond(writer http.ResponseWriter, request *http.Request, prepared view) {
+	name := request.URL.Query().Get("format")
+	if name == "" {
+		negotiated, ok := format.Negotiate(request.Header.Get("Accept"))
+		if !ok {
+			http.Error(writer, errNotAcceptable.Error(), http.StatusNotAcceptable)
+
+			return
+		}
+
+		name = negotiated
+	}
+
+	encoder, err := format.ByName(name)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	encoder, err = format.WithFields(encoder, prepared.fields)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var body bytes.Buffer
+
+	err = encoder.Encode(&body, prepared.currencies)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+
+	writer.Header().Set("Content-Type", format.ContentType(name))
+	writer.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])[:etagLen]+`"`)
+	writer.Header().Add("Vary", "Accept")
+
+	http.ServeContent(writer, request, "", prepared.modTime, bytes.NewReader(body.Bytes()))
+}
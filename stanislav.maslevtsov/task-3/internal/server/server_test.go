@@ -0,0 +1,319 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+	"github.com/jambii1/task-3/internal/server"
+	"github.com/jambii1/task-3/internal/source"
+)
+
+type staticSource struct {
+	currencies *currency.Currencies
+	modTime    time.Time
+}
+
+func (source staticSource) Fetch(context.Context, time.Time) (*currency.Currencies, error) {
+	data := make([]*currency.Currency, 0, len(source.currencies.Data))
+	for _, cur := range source.currencies.Data {
+		copied := *cur
+		data = append(data, &copied)
+	}
+
+	return &currency.Currencies{Date: source.currencies.Date, Name: source.currencies.Name, Data: data}, nil
+}
+
+func (source staticSource) ModTime() (time.Time, error) {
+	return source.modTime, nil
+}
+
+// countingSource counts the fetches that got past the server cache.
+type countingSource struct {
+	staticSource
+	fetches *atomic.Int32
+}
+
+func (source countingSource) Fetch(ctx context.Context, date time.Time) (*currency.Currencies, error) {
+	source.fetches.Add(1)
+
+	return source.staticSource.Fetch(ctx, date)
+}
+
+func newTestServer(t *testing.T, opts ...server.Option) *httptest.Server {
+	t.Helper()
+
+	return serveRates(t, testRates(), opts...)
+}
+
+func serveRates(t *testing.T, rates source.RateSource, opts ...server.Option) *httptest.Server {
+	t.Helper()
+
+	testServer := httptest.NewServer(server.New(rates, config.QueryRecord{}, opts...).Handler())
+	t.Cleanup(testServer.Close)
+
+	return testServer
+}
+
+func testRates() staticSource {
+	return staticSource{
+		currencies: &currency.Currencies{
+			Date: "02.03.2002",
+			Name: "Foreign Currency Market",
+			Data: []*currency.Currency{
+				{ID: "R01235", NumCode: 840, CharCode: "USD", Nominal: 1, Name: "Доллар США", Value: decimal.New(309436, 4)},
+				{ID: "R01239", NumCode: 978, CharCode: "EUR", Nominal: 1, Name: "Евро", Value: decimal.New(268343, 4)},
+			},
+		},
+		modTime: time.Date(2002, time.March, 2, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func get(t *testing.T, url string, header http.Header) *http.Response {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", url, err)
+	}
+
+	t.Cleanup(func() {
+		_ = response.Body.Close()
+	})
+
+	return response
+}
+
+func TestNegotiation(t *testing.T) {
+	testServer := newTestServer(t)
+
+	tests := []struct {
+		accept      string
+		query       string
+		status      int
+		contentType string
+	}{
+		{accept: "", status: http.StatusOK, contentType: "application/json"},
+		{accept: "application/json", status: http.StatusOK, contentType: "application/json"},
+		{accept: "application/xml", status: http.StatusOK, contentType: "application/xml"},
+		{accept: "text/csv;q=0.5, application/yaml", status: http.StatusOK, contentType: "application/yaml"},
+		{accept: "text/*", status: http.StatusOK, contentType: "text/csv; charset=utf-8"},
+		{accept: "*/*", status: http.StatusOK, contentType: "application/json"},
+		{accept: "image/png", status: http.StatusNotAcceptable},
+		{accept: "image/png", query: "?format=csv", status: http.StatusOK, contentType: "text/csv; charset=utf-8"},
+		{accept: "application/vnd.cbr+xml", status: http.StatusOK, contentType: "application/vnd.cbr+xml; charset=windows-1251"},
+	}
+
+	for _, test := range tests {
+		response := get(t, testServer.URL+"/rates"+test.query, http.Header{"Accept": {test.accept}})
+
+		if response.StatusCode != test.status {
+			t.Errorf("Accept %q: got status %d, want %d", test.accept, response.StatusCode, test.status)
+
+			continue
+		}
+
+		contentType := response.Header.Get("Content-Type")
+		if test.contentType != "" && contentType != test.contentType {
+			t.Errorf("Accept %q: got Content-Type %q, want %q", test.accept, contentType, test.contentType)
+		}
+	}
+}
+
+func TestJSONKeepsRecordShape(t *testing.T) {
+	response := get(t, newTestServer(t).URL+"/rates", http.Header{"Accept": {"application/json"}})
+
+	var records []map[string]any
+
+	err := json.NewDecoder(response.Body).Decode(&records)
+	if err != nil {
+		t.Fatalf("failed to decode records: %v", err)
+	}
+
+	if len(records) != 2 || records[0]["char_code"] != "USD" {
+		t.Errorf("got records %v, want USD first of two", records)
+	}
+}
+
+func TestXMLIsUTF8(t *testing.T) {
+	response := get(t, newTestServer(t).URL+"/rates", http.Header{"Accept": {"application/xml"}})
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+
+	if !strings.HasPrefix(string(body), xml.Header) {
+		t.Errorf("got body %q, want the utf-8 xml format", body)
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	testServer := newTestServer(t)
+
+	first := get(t, testServer.URL+"/rates", nil)
+
+	etag := first.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("response has no ETag")
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{name: "matching etag", header: http.Header{"If-None-Match": {etag}}, status: http.StatusNotModified},
+		{name: "other etag", header: http.Header{"If-None-Match": {`"other"`}}, status: http.StatusOK},
+		{
+			name:   "not modified since",
+			header: http.Header{"If-Modified-Since": {first.Header.Get("Last-Modified")}},
+			status: http.StatusNotModified,
+		},
+	}
+
+	for _, test := range tests {
+		response := get(t, testServer.URL+"/rates", test.header)
+		if response.StatusCode != test.status {
+			t.Errorf("%s: got status %d, want %d", test.name, response.StatusCode, test.status)
+		}
+	}
+
+	other := get(t, testServer.URL+"/rates", http.Header{"Accept": {"text/csv"}})
+	if other.Header.Get("ETag") == etag {
+		t.Error("csv and json responses share an ETag")
+	}
+}
+
+func TestRate(t *testing.T) {
+	testServer := newTestServer(t)
+
+	response := get(t, testServer.URL+"/rates/eur", http.Header{"Accept": {"text/csv"}})
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", response.StatusCode, http.StatusOK)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+
+	want := "num_code,char_code,value\n978,EUR,26.8343\n"
+	if string(body) != want {
+		t.Errorf("got body %q, want %q", body, want)
+	}
+
+	missing := get(t, testServer.URL+"/rates/XXX", nil)
+	if missing.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown code: got status %d, want %d", missing.StatusCode, http.StatusNotFound)
+	}
+
+	badDate := get(t, testServer.URL+"/rates/USD?date=2002-03-02", nil)
+	if badDate.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad date: got status %d, want %d", badDate.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func readBody(t *testing.T, response *http.Response) string {
+	t.Helper()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+
+	return string(body)
+}
+
+func TestFieldsAndBaseCurrency(t *testing.T) {
+	testServer := newTestServer(t)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "fields",
+			path: "/rates?format=csv&fields=char_code,nominal",
+			want: "char_code,nominal\nUSD,1\nEUR,1\n",
+		},
+		{
+			name: "base currency",
+			path: "/rates?format=csv&base-currency=USD&fields=char_code,value,inverse",
+			want: "char_code,value,inverse\nUSD,1,1\nEUR,0.8672,1.153136\nRUB,0.032317,30.9436\n",
+		},
+		{
+			name: "cached snapshot is not rebased",
+			path: "/rates?format=csv",
+			want: "num_code,char_code,value\n840,USD,30.9436\n978,EUR,26.8343\n",
+		},
+	}
+
+	for _, test := range tests {
+		response := get(t, testServer.URL+test.path, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%s: got status %d, want %d", test.name, response.StatusCode, http.StatusOK)
+		}
+
+		body := readBody(t, response)
+		if body != test.want {
+			t.Errorf("%s: got body %q, want %q", test.name, body, test.want)
+		}
+	}
+
+	for _, path := range []string{"/rates?fields=unknown", "/rates?base-currency=XXX"} {
+		response := get(t, testServer.URL+path, nil)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d", path, response.StatusCode, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	rates := countingSource{staticSource: testRates(), fetches: &atomic.Int32{}}
+	testServer := serveRates(t, rates, server.WithCacheSize(2))
+
+	tests := []struct {
+		date    string
+		fetches int32
+	}{
+		{date: "01.03.2002", fetches: 1},
+		{date: "02.03.2002", fetches: 2},
+		{date: "01.03.2002", fetches: 2},
+		{date: "03.03.2002", fetches: 3},
+		{date: "01.03.2002", fetches: 3},
+		{date: "02.03.2002", fetches: 4},
+	}
+
+	for _, test := range tests {
+		response := get(t, testServer.URL+"/rates?date="+test.date, nil)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%s: got status %d, want %d", test.date, response.StatusCode, http.StatusOK)
+		}
+
+		got := rates.fetches.Load()
+		if got != test.fetches {
+			t.Errorf("after %s: got %d fetches, want %d", test.date, got, test.fetches)
+		}
+	}
+}
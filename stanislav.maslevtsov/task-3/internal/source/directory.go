@@ -0,0 +1,121 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jambii1/task-3/internal/currency"
+)
+
+type Directory struct {
+	path   string
+	decode DecodeFunc
+}
+
+func NewDirectory(path string, opts ...Option) *Directory {
+	return &Directory{path: path, decode: newOptions(opts).decode}
+}
+
+func (directory *Directory) Fetch(ctx context.Context, date time.Time) (*currency.Currencies, error) {
+	snapshots, err := directory.Snapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("%w %s in %s", ErrNoSnapshot, date.Format(currency.DateLayout), directory.path)
+	}
+
+	if date.IsZero() {
+		return snapshots[len(snapshots)-1].Currencies, nil
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.Date.Equal(date) {
+			return snapshot.Currencies, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w %s in %s", ErrNoSnapshot, date.Format(currency.DateLayout), directory.path)
+}
+
+type Snapshot struct {
+	Path       string
+	Date       time.Time
+	Currencies *currency.Currencies
+}
+
+// Snapshots decodes every xml file of the directory and orders them by date.
+func (directory *Directory) Snapshots(ctx context.Context) ([]Snapshot, error) {
+	entries, err := os.ReadDir(directory.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots directory: %w", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".xml") {
+			continue
+		}
+
+		err = ctx.Err()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshots: %w", err)
+		}
+
+		path := filepath.Join(directory.path, entry.Name())
+
+		currencies, err := decodeFile(path, directory.decode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+		}
+
+		date, err := currencies.ParseDate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+		}
+
+		snapshots = append(snapshots, Snapshot{Path: path, Date: date, Currencies: currencies})
+	}
+
+	slices.SortFunc(snapshots, func(left, right Snapshot) int {
+		return left.Date.Compare(right.Date)
+	})
+
+	return snapshots, nil
+}
+
+// ModTime is the latest modification of the directory itself or any of its files,
+// so both added and rewritten snapshots are noticed.
+func (directory *Directory) ModTime() (time.Time, error) {
+	info, err := os.Stat(directory.path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get directory info: %w", err)
+	}
+
+	latest := info.ModTime()
+
+	entries, err := os.ReadDir(directory.path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read snapshots directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		entryInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		if entryInfo.ModTime().After(latest) {
+			latest = entryInfo.ModTime()
+		}
+	}
+
+	return latest, nil
+}
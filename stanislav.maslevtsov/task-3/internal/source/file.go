@@ -0,0 +1,44 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jambii1/task-3/internal/currency"
+)
+
+type File struct {
+	path   string
+	decode DecodeFunc
+}
+
+func NewFile(path string, opts ...Option) *File {
+	return &File{path: path, decode: newOptions(opts).decode}
+}
+
+func (file *File) Fetch(_ context.Context, date time.Time) (*currency.Currencies, error) {
+	currencies, err := decodeFile(file.path, file.decode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from file: %w", err)
+	}
+
+	if !date.IsZero() {
+		snapshotDate, err := currencies.ParseDate()
+		if err != nil || !snapshotDate.Equal(date) {
+			return nil, fmt.Errorf("%w %s in %s", ErrNoSnapshot, date.Format(currency.DateLayout), file.path)
+		}
+	}
+
+	return currencies, nil
+}
+
+func (file *File) ModTime() (time.Time, error) {
+	info, err := os.Stat(file.path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	return info.ModTime(), nil
+}
@@ -0,0 +1,80 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jambii1/task-3/internal/currency"
+)
+
+const (
+	defaultTimeout  = 10 * time.Second
+	dateQueryKey    = "date_req"
+	dateQueryLayout = "02/01/2006"
+)
+
+var ErrUnexpectedStatus = errors.New("unexpected http status")
+
+type HTTP struct {
+	url    string
+	client *http.Client
+	decode DecodeFunc
+}
+
+func NewHTTP(rawURL string, timeout time.Duration, opts ...Option) *HTTP {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return NewHTTPWithClient(rawURL, &http.Client{Timeout: timeout}, opts...)
+}
+
+func NewHTTPWithClient(rawURL string, client *http.Client, opts ...Option) *HTTP {
+	return &HTTP{url: rawURL, client: client, decode: newOptions(opts).decode}
+}
+
+func (source *HTTP) Fetch(ctx context.Context, date time.Time) (*currency.Currencies, error) {
+	requestURL, err := url.Parse(source.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rate source url: %w", err)
+	}
+
+	if !date.IsZero() {
+		query := requestURL.Query()
+		query.Set(dateQueryKey, date.Format(dateQueryLayout))
+		requestURL.RawQuery = query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rate source request: %w", err)
+	}
+
+	response, err := source.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request rate source: %w", err)
+	}
+
+	var currencies *currency.Currencies
+
+	if response.StatusCode != http.StatusOK {
+		err = fmt.Errorf("%w: %s", ErrUnexpectedStatus, response.Status)
+	} else {
+		currencies, err = source.decode(response.Body)
+	}
+
+	closeErr := response.Body.Close()
+
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch from http: %w", err)
+	case closeErr != nil:
+		return nil, fmt.Errorf("failed to close http response: %w", closeErr)
+	}
+
+	return currencies, nil
+}
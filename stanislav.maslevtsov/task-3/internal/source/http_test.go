@@ -0,0 +1,95 @@
+package source_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jambii1/task-3/internal/source"
+)
+
+const dailyXML = `<?xml version="1.0" encoding="utf-8"?>
+<ValCurs Date="02.03.2002" name="Foreign Currency Market">
+<Valute ID="R01235"><NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal><Name>Доллар США</Name><Value>30,9436</Value></Valute>
+</ValCurs>
+`
+
+func TestHTTPFetch(t *testing.T) {
+	var dateQuery string
+
+	stub := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		dateQuery = request.URL.Query().Get("date_req")
+
+		_, _ = writer.Write([]byte(dailyXML))
+	}))
+	t.Cleanup(stub.Close)
+
+	rateSource := source.NewHTTPWithClient(stub.URL+"/scripts/XML_daily.asp", stub.Client())
+
+	currencies, err := rateSource.Fetch(context.Background(), time.Date(2002, time.March, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("failed to fetch: %v", err)
+	}
+
+	if dateQuery != "02/03/2002" {
+		t.Errorf("got date_req %q, want %q", dateQuery, "02/03/2002")
+	}
+
+	if currencies.Date != "02.03.2002" || len(currencies.Data) != 1 || currencies.Data[0].CharCode != "USD" {
+		t.Errorf("got %+v, want the USD rate of 02.03.2002", currencies)
+	}
+}
+
+func TestHTTPUnexpectedStatus(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "maintenance", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(stub.Close)
+
+	_, err := source.NewHTTPWithClient(stub.URL, stub.Client()).Fetch(context.Background(), time.Time{})
+	if !errors.Is(err, source.ErrUnexpectedStatus) {
+		t.Errorf("got error %v, want %v", err, source.ErrUnexpectedStatus)
+	}
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+
+	release := make(chan struct{})
+
+	stub := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-release:
+		case <-request.Context().Done():
+		}
+
+		_, _ = writer.Write([]byte(dailyXML))
+	}))
+	t.Cleanup(stub.Close)
+	t.Cleanup(func() {
+		close(release)
+	})
+
+	client := stub.Client()
+	client.Timeout = timeout
+
+	started := time.Now()
+
+	_, err := source.NewHTTPWithClient(stub.URL, client).Fetch(context.Background(), time.Time{})
+	if err == nil {
+		t.Fatal("fetch succeeded, want a timeout")
+	}
+
+	var netErr interface{ Timeout() bool }
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("got error %v, want a timeout", err)
+	}
+
+	elapsed := time.Since(started)
+	if elapsed > 20*timeout {
+		t.Errorf("fetch took %s, want it cut at about %s", elapsed, timeout)
+	}
+}
@@ -0,0 +1,101 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jambii1/task-3/internal/config"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/feed"
+)
+
+const (
+	TypeFile      = "file"
+	TypeDirectory = "directory"
+	TypeHTTP      = "http"
+)
+
+var (
+	ErrUnknownType = errors.New("unknown rate source type")
+	ErrNoSnapshot  = errors.New("no rate snapshot for date")
+)
+
+type (
+	DecodeFunc func(input io.Reader) (*currency.Currencies, error)
+
+	Option func(opts *options)
+
+	options struct {
+		decode DecodeFunc
+	}
+)
+
+// WithDecoder replaces currency.Decode, e.g. with a validating decoder. It is
+// used for CBR input only, other feeds are told apart by their root element.
+func WithDecoder(decode DecodeFunc) Option {
+	return func(opts *options) {
+		opts.decode = decode
+	}
+}
+
+func newOptions(opts []Option) options {
+	result := options{decode: currency.Decode}
+	for _, opt := range opts {
+		opt(&result)
+	}
+
+	result.decode = feed.Decoder(feed.NewCBR(result.decode), feed.ECB{}, feed.NBU{})
+
+	return result
+}
+
+func decodeFile(path string, decode DecodeFunc) (*currency.Currencies, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open xml currencies file: %w", err)
+	}
+
+	currencies, err := decode(file)
+	closeErr := file.Close()
+
+	switch {
+	case err != nil:
+		return nil, err
+	case closeErr != nil:
+		return nil, fmt.Errorf("failed to close xml currencies file: %w", closeErr)
+	}
+
+	return currencies, nil
+}
+
+// RateSource provides a ValCurs snapshot. A zero date asks for the latest one.
+type RateSource interface {
+	Fetch(ctx context.Context, date time.Time) (*currency.Currencies, error)
+}
+
+// Modified is implemented by sources that can tell when their data last changed.
+type Modified interface {
+	ModTime() (time.Time, error)
+}
+
+func New(conRec *config.ConfigRecord, opts ...Option) (RateSource, error) {
+	switch conRec.Source.Type {
+	case "", TypeFile:
+		path := conRec.Source.Path
+		if path == "" {
+			path = conRec.InputFile
+		}
+
+		return NewFile(path, opts...), nil
+	case TypeDirectory:
+		return NewDirectory(conRec.Source.Path, opts...), nil
+	case TypeHTTP:
+		return NewHTTP(conRec.Source.URL, conRec.Source.Timeout, opts...), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, conRec.Source.Type)
+	}
+}
@@ -0,0 +1,57 @@
+package textio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Writer keeps the first write error and skips every later write, so text
+// formats written piece by piece check for an error once at the end.
+type Writer struct {
+	writer io.Writer
+	err    error
+}
+
+func NewWriter(writer io.Writer) *Writer {
+	return &Writer{writer: writer, err: nil}
+}
+
+func (writer *Writer) Printf(format string, args ...any) {
+	if writer.err != nil {
+		return
+	}
+
+	_, writer.err = fmt.Fprintf(writer.writer, format, args...)
+}
+
+func (writer *Writer) Err() error {
+	return writer.err
+}
+
+// EscapeXML makes the text safe as xml character data and attribute values.
+func EscapeXML(text string) string {
+	var builder strings.Builder
+
+	_ = xml.EscapeText(&builder, []byte(text))
+
+	return builder.String()
+}
+
+// FormatFloat writes the shortest decimal that reads back as the value, without
+// an exponent, and infinities as +Inf and -Inf.
+func FormatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
@@ -0,0 +1,60 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jambii1/task-3/internal/currency"
+)
+
+const (
+	ModeNone    = ""
+	ModeStrict  = "strict"
+	ModeLenient = "lenient"
+)
+
+var (
+	ErrInvalidInput = errors.New("input file has validation errors")
+	ErrUnknownMode  = errors.New("unknown validation mode")
+)
+
+func (issue Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s: %s", issue.Line, issue.Column, issue.Severity, issue.Code, issue.Message)
+}
+
+// Decoder returns a decode function for the mode: strict fails on any error,
+// lenient skips broken records. Issues of both modes are handed to the report
+// function. An empty encoding is detected from the input.
+func Decoder(mode, encoding string, report func(issue Issue)) (func(io.Reader) (*currency.Currencies, error), error) {
+	switch mode {
+	case ModeNone:
+		return func(input io.Reader) (*currency.Currencies, error) {
+			return currency.DecodeCharset(input, encoding)
+		}, nil
+	case ModeStrict, ModeLenient:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMode, mode)
+	}
+
+	return func(input io.Reader) (*currency.Currencies, error) {
+		currencies, checkReport, err := Check(input, encoding)
+		if checkReport == nil {
+			return nil, err
+		}
+
+		for _, issue := range checkReport.Issues {
+			report(issue)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if mode == ModeStrict && checkReport.HasErrors() {
+			return nil, fmt.Errorf("%w: %d errors", ErrInvalidInput, checkReport.Errors)
+		}
+
+		return currencies, nil
+	}, nil
+}
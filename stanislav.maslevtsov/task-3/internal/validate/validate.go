@@ -0,0 +1,362 @@
+package validate
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jambii1/task-3/internal/charset"
+	"github.com/jambii1/task-3/internal/currency"
+	"github.com/jambii1/task-3/internal/decimal"
+	"github.com/jambii1/task-3/internal/iso4217"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+var requiredElements = []string{"NumCode", "CharCode", "Nominal", "Name", "Value"}
+
+type (
+	Issue struct {
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+		Severity string `json:"severity"`
+		Code     string `json:"code"`
+		CharCode string `json:"char_code,omitempty"`
+		Message  string `json:"message"`
+	}
+
+	Report struct {
+		Date     string            `json:"date"`
+		Encoding charset.Detection `json:"encoding"`
+		Records  int               `json:"records"`
+		Valid    int               `json:"valid"`
+		Errors   int               `json:"errors"`
+		Issues   []Issue           `json:"issues"`
+	}
+
+	position struct {
+		line   int
+		column int
+	}
+
+	element struct {
+		text string
+		position
+	}
+
+	valute struct {
+		id       string
+		start    position
+		elements map[string]element
+	}
+
+	checker struct {
+		report     *Report
+		decoder    *xml.Decoder
+		tokenStart position
+		seenCodes  map[string]position
+		result     *currency.Currencies
+		date       time.Time
+	}
+)
+
+func (report *Report) HasErrors() bool {
+	return report.Errors > 0
+}
+
+func (report *Report) add(pos position, severity, code, charCode, message string) {
+	report.Issues = append(report.Issues, Issue{
+		Line:     pos.line,
+		Column:   pos.column,
+		Severity: severity,
+		Code:     code,
+		CharCode: charCode,
+		Message:  message,
+	})
+
+	if severity == SeverityError {
+		report.Errors++
+	}
+}
+
+// Check reads the whole input and reports every issue it finds instead of stopping
+// at the first one. The returned currencies hold only records without errors.
+// An empty encoding is detected from the input.
+func Check(input io.Reader, encoding string) (*currency.Currencies, *Report, error) {
+	decoder, detection, err := currency.NewDecoder(input, encoding)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	check := &checker{
+		report:     &Report{Encoding: detection, Issues: []Issue{}},
+		decoder:    decoder,
+		tokenStart: position{line: 1, column: 1},
+		seenCodes:  make(map[string]position),
+		result:     &currency.Currencies{Encoding: detection.Encoding},
+	}
+
+	err = check.run()
+	if err != nil {
+		return nil, check.report, err
+	}
+
+	return check.result, check.report, nil
+}
+
+func (check *checker) position() position {
+	line, column := check.decoder.InputPos()
+
+	return position{line: line, column: column}
+}
+
+// token reads the next token and remembers where it starts. Text between tags
+// is a token of its own, so the position before the read is the "<" of a tag,
+// while the decoder is already past the tag once it returns.
+func (check *checker) token() (xml.Token, error) {
+	check.tokenStart = check.position()
+
+	return check.decoder.Token()
+}
+
+func (check *checker) run() error {
+	isRoot := true
+
+	for {
+		token, err := check.token()
+		if errors.Is(err, io.EOF) && !isRoot {
+			return nil
+		}
+
+		if err != nil {
+			pos := check.position()
+			check.report.add(pos, SeverityError, "malformed_xml", "", err.Error())
+
+			return fmt.Errorf("failed to read xml token at %d:%d: %w", pos.line, pos.column, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case start.Name.Local == "Valute":
+			err = check.readValute(start)
+			if err != nil {
+				return err
+			}
+		case isRoot || start.Name.Local == "ValCurs":
+			check.checkHeader(start)
+		}
+
+		isRoot = false
+	}
+}
+
+func (check *checker) checkHeader(start xml.StartElement) {
+	pos := check.tokenStart
+
+	if start.Name.Local != "ValCurs" {
+		check.report.add(pos, SeverityWarning, "unexpected_root", "",
+			fmt.Sprintf("root element is %s, ValCurs expected", start.Name.Local))
+	}
+
+	header := currency.Header{}
+
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "Date":
+			header.Date = attr.Value
+		case "name":
+			header.Name = attr.Value
+		}
+	}
+
+	date, err := time.Parse(currency.DateLayout, header.Date)
+	check.date = date
+
+	switch {
+	case header.Date == "":
+		check.report.add(pos, SeverityError, "missing_date", "", "ValCurs has no Date attribute")
+	case err != nil:
+		check.report.add(pos, SeverityError, "invalid_date", "", fmt.Sprintf("Date %q is not DD.MM.YYYY", header.Date))
+	}
+
+	if check.report.Date == "" {
+		check.report.Date = header.Date
+		check.result.Date, check.result.Name = header.Date, header.Name
+	}
+}
+
+func (check *checker) readValute(start xml.StartElement) error {
+	current := valute{start: check.tokenStart, elements: make(map[string]element)}
+
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "ID" {
+			current.id = attr.Value
+		}
+	}
+
+	var (
+		name         string
+		text         strings.Builder
+		elementStart position
+		depth        = 0
+	)
+
+	for {
+		token, err := check.token()
+		if err != nil {
+			pos := check.position()
+			check.report.add(pos, SeverityError, "malformed_xml", "", err.Error())
+
+			return fmt.Errorf("failed to read valute at %d:%d: %w", pos.line, pos.column, err)
+		}
+
+		switch typed := token.(type) {
+		case xml.StartElement:
+			depth++
+			name = typed.Name.Local
+			elementStart = check.tokenStart
+			text.Reset()
+		case xml.CharData:
+			text.Write(typed)
+		case xml.EndElement:
+			if depth == 0 {
+				check.checkValute(current)
+
+				return nil
+			}
+
+			_, duplicate := current.elements[name]
+			if duplicate {
+				check.report.add(elementStart, SeverityWarning, "duplicate_element", "",
+					fmt.Sprintf("element %s repeats, the last one is used", name))
+			}
+
+			current.elements[name] = element{text: strings.TrimSpace(text.String()), position: elementStart}
+			depth--
+		}
+	}
+}
+
+func (check *checker) checkValute(current valute) {
+	check.report.Records++
+
+	charCode := current.elements["CharCode"].text
+	issuesBefore := check.report.Errors
+
+	for _, required := range requiredElements {
+		_, ok := current.elements[required]
+		if !ok {
+			check.report.add(current.start, SeverityError, "missing_element", charCode,
+				fmt.Sprintf("Valute has no %s element", required))
+		}
+	}
+
+	cur := &currency.Currency{ID: current.id, CharCode: charCode, Name: current.elements["Name"].text}
+
+	check.checkCharCode(current)
+	check.checkNumbers(current, cur)
+
+	if check.report.Errors == issuesBefore {
+		check.report.Valid++
+		check.result.Data = append(check.result.Data, cur)
+	}
+}
+
+func (check *checker) checkCharCode(current valute) {
+	const charCodeLen = 3
+
+	charCode, ok := current.elements["CharCode"]
+	if !ok {
+		return
+	}
+
+	if len(charCode.text) != charCodeLen || strings.ToUpper(charCode.text) != charCode.text {
+		check.report.add(charCode.position, SeverityError, "invalid_char_code", charCode.text,
+			fmt.Sprintf("CharCode %q is not three upper case letters", charCode.text))
+
+		return
+	}
+
+	first, duplicate := check.seenCodes[charCode.text]
+	if duplicate {
+		check.report.add(charCode.position, SeverityError, "duplicate_code", charCode.text,
+			fmt.Sprintf("CharCode %s already appeared at %d:%d", charCode.text, first.line, first.column))
+	} else {
+		check.seenCodes[charCode.text] = charCode.position
+	}
+
+	numCode, ok := current.elements["NumCode"]
+	if !ok {
+		return
+	}
+
+	numeric, err := strconv.ParseUint(numCode.text, 10, 16)
+	if err != nil {
+		return
+	}
+
+	entry, status := iso4217.Lookup(charCode.text, uint(numeric))
+
+	switch {
+	case status == iso4217.StatusUnknown:
+		check.report.add(charCode.position, SeverityWarning, "unknown_code", charCode.text,
+			fmt.Sprintf("CharCode %s is not in ISO 4217", charCode.text))
+	case status == iso4217.StatusMismatch:
+		check.report.add(numCode.position, SeverityError, "code_mismatch", charCode.text,
+			fmt.Sprintf("NumCode %s does not belong to %s in ISO 4217", numCode.text, charCode.text))
+	case status == iso4217.StatusWithdrawn && !check.date.IsZero() && entry.WithdrawnBy(check.date):
+		check.report.add(charCode.position, SeverityWarning, "withdrawn_code", charCode.text,
+			fmt.Sprintf("CharCode %s was withdrawn from ISO 4217 in %s", charCode.text, entry.Withdrawn))
+	}
+}
+
+func (check *checker) checkNumbers(current valute, cur *currency.Currency) {
+	numCode, ok := current.elements["NumCode"]
+	if ok {
+		numeric, err := strconv.ParseUint(numCode.text, 10, 16)
+		if err != nil {
+			check.report.add(numCode.position, SeverityError, "invalid_num_code", cur.CharCode,
+				fmt.Sprintf("NumCode %q is not a number", numCode.text))
+		}
+
+		cur.NumCode = uint(numeric)
+	}
+
+	nominal, ok := current.elements["Nominal"]
+	if ok {
+		parsed, err := strconv.ParseUint(nominal.text, 10, 32)
+		if err != nil || parsed == 0 {
+			check.report.add(nominal.position, SeverityError, "invalid_nominal", cur.CharCode,
+				fmt.Sprintf("Nominal %q is not a positive integer", nominal.text))
+		}
+
+		cur.Nominal = uint(parsed)
+	}
+
+	value, ok := current.elements["Value"]
+	if ok {
+		parsed, err := decimal.Parse(value.text)
+
+		switch {
+		case err != nil:
+			check.report.add(value.position, SeverityError, "invalid_value", cur.CharCode,
+				fmt.Sprintf("Value %q is not a decimal", value.text))
+		case parsed.Sign() <= 0:
+			check.report.add(value.position, SeverityError, "non_positive_value", cur.CharCode,
+				fmt.Sprintf("Value %s is not positive", value.text))
+		}
+
+		cur.Value = parsed
+	}
+}